@@ -7,10 +7,13 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"github.com/carneades/carneades-4/src/engine/dung"
+	"github.com/carneades/carneades-4/src/engine/dung/encoding/apx"
 	"github.com/carneades/carneades-4/src/engine/dung/encoding/graphml"
+	"github.com/carneades/carneades-4/src/engine/dung/encoding/i23"
 	"github.com/carneades/carneades-4/src/engine/dung/encoding/tgf"
 	"log"
 	"os"
@@ -19,10 +22,10 @@ import (
 )
 
 const name = "Carneades ICCMA"
-const version = "v1.0"
+const version = "v2.0"
 const author = "Tom Gordon (thomas.gordon@fokus.fraunhofer.de)"
-const formats = "[tgf]"
-const problems = "[DC-GR,DS-GR,EE-GR,SE-GR,DC-PR,DS-PR,EE-PR,SE-PR,DC-CO,DS-CO,EE-CO,SE-CO,DC-ST,DS-ST,EE-ST,SE-ST]"
+const formats = "[i23,apx,tgf]"
+const problems = "[DC-GR,DS-GR,EE-GR,SE-GR,CE-GR,VE-GR,DC-PR,DS-PR,EE-PR,SE-PR,CE-PR,VE-PR,DC-CO,DS-CO,EE-CO,SE-CO,CE-CO,VE-CO,DC-ST,DS-ST,EE-ST,SE-ST,CE-ST,VE-ST,DC-SST,DS-SST,EE-SST,SE-SST,CE-SST,VE-SST,DC-STG,DS-STG,EE-STG,SE-STG,CE-STG,VE-STG,DC-ID,DS-ID,EE-ID,SE-ID,CE-ID,VE-ID,DC-EG,DS-EG,EE-EG,SE-EG,CE-EG,VE-EG,DC-NA,DS-NA,EE-NA,SE-NA,CE-NA,VE-NA,DC-AD,DS-AD,EE-AD,SE-AD,CE-AD,VE-AD,DC-CF2,DS-CF2,EE-CF2,SE-CF2,CE-CF2,VE-CF2,DC-STG2,DS-STG2,EE-STG2,SE-STG2,CE-STG2,VE-STG2]"
 
 func main() {
 	if len(os.Args) == 1 {
@@ -34,9 +37,13 @@ func main() {
 	problemsFlag := flag.Bool("problems", false, "print supported problems")
 	problemFlag := flag.String("p", "DC-GR", "the problem to solve")
 	fileFlag := flag.String("f", "", "the source file for the AF")
-	formatFlag := flag.String("fo", "tgf", "the format of the source file")
+	formatFlag := flag.String("fo", "", "the format of the source file [i23,apx,tgf], by default determined by the file extension")
 	argFlag := flag.String("a", "", "the id of the argument to check")
+	setFlag := flag.String("e", "", "the comma-separated ids of the arguments of the set to verify (VE)")
+	solverFlag := flag.String("solver", "backtracking", "the solver to use [backtracking,scc,subsets]")
 	outputFlag := flag.String("o", "", "the name of a directory to create to output GraphML")
+	timeoutFlag := flag.Duration("timeout", 0, "the time limit for enumerating extensions (EE), e.g. 10s, or 0 for no limit")
+	limitFlag := flag.Int("limit", 0, "the maximum number of extensions to enumerate (EE), or 0 for no limit")
 
 	flag.Parse()
 
@@ -58,11 +65,6 @@ func main() {
 		return
 	}
 
-	if *formatFlag != "tgf" {
-		log.Fatal(fmt.Errorf("unsupported format: %s\n", *formatFlag))
-		return
-	}
-
 	var inFile *os.File
 	var err error
 	var af dung.AF
@@ -73,103 +75,170 @@ func main() {
 		return
 	}
 
-	if *formatFlag == "tgf" {
-		inFile, err = os.Open(*fileFlag)
-		if err != nil {
-			log.Fatal(err)
+	format := *formatFlag
+	if format == "" {
+		// ICCMA 2023 instances in the i23 format usually have the
+		// extension .af or .i23
+		switch strings.ToLower(filepath.Ext(*fileFlag)) {
+		case ".tgf":
+			format = "tgf"
+		case ".apx":
+			format = "apx"
+		default:
+			format = "i23"
 		}
+	}
+
+	inFile, err = os.Open(*fileFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	switch format {
+	case "i23":
+		af, err = i23.Import(inFile)
+	case "apx":
+		af, err = apx.Import(inFile)
+	case "tgf":
 		af, err = tgf.Import(inFile)
-		if err != nil {
-			log.Fatal(err)
+	default:
+		log.Fatal(fmt.Errorf("unsupported format: %s\n", format))
+		return
+	}
+	inFile.Close()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Returns the argument with the given id, written as in the AF
+	argument := func(id string) dung.Arg {
+		a := dung.Arg(id)
+		if format == "i23" {
+			a = i23.CanonicalArg(id)
 		}
+		for _, b := range af.Args() {
+			if a == b {
+				return a
+			}
+		}
+		log.Fatal(fmt.Errorf("unknown argument: %s\n", id))
+		return a
 	}
 
-	printExtensions := func(extensions []dung.ArgSet) {
-		s := []string{}
-		for _, E := range extensions {
-			s = append(s, E.String())
+	switch *solverFlag {
+	case "backtracking":
+		af.SetSolver(dung.BacktrackingSolver)
+	case "subsets":
+		af.SetSolver(dung.SubsetSolver)
+	case "scc":
+		af.SetSolver(dung.SCCSolver)
+	default:
+		log.Fatal(fmt.Errorf("unsupported solver: %s\n", *solverFlag))
+		return
+	}
+
+	// Print an extension as a witness line, "w a1 a2 ...", listing its
+	// arguments in the order in which they were declared in the source file.
+	printWitness := func(E dung.ArgSet) {
+		s := []string{"w"}
+		for _, arg := range af.Args() {
+			if E.Contains(arg) {
+				s = append(s, string(arg))
+			}
 		}
-		fmt.Printf("[%s]\n", strings.Join(s, ","))
+		fmt.Printf("%s\n", strings.Join(s, " "))
 	}
 
 	printExtension := func(E dung.ArgSet, exists bool) {
 		if exists {
-			fmt.Printf("%s\n", E)
+			printWitness(E)
 		} else {
 			fmt.Printf("NO\n")
 		}
 	}
 
-	printBool := func(b bool) {
-		if b {
-			fmt.Printf("YES\n")
-		} else {
-			fmt.Printf("NO\n")
-		}
+	if *problemFlag == "traverse" {
+		af.Traverse(func(E dung.ArgSet) {
+			fmt.Printf("%v\n", E)
+		})
+		return
 	}
 
-	// Grounded Semantics
-	if *problemFlag == "DC-GR" {
-		checkArgFlag()
-		printBool(af.CredulouslyInferred(dung.Grounded, dung.Arg(arg)))
-	} else if *problemFlag == "DS-GR" {
-		checkArgFlag()
-		printBool(af.SkepticallyInferred(dung.Grounded, dung.Arg(arg)))
-	} else if *problemFlag == "EE-GR" {
-		E := af.GroundedExtension()
-		extensions = []dung.ArgSet{E}
-		fmt.Printf("[%s]\n", E)
-	} else if *problemFlag == "SE-GR" {
-		E := af.GroundedExtension()
-		extensions = []dung.ArgSet{E}
-		fmt.Printf("%s\n", E)
-
-		// Preferred Semantics
-	} else if *problemFlag == "DC-PR" {
-		checkArgFlag()
-		printBool(af.CredulouslyInferred(dung.Preferred, dung.Arg(arg)))
-	} else if *problemFlag == "DS-PR" {
-		checkArgFlag()
-		printBool(af.SkepticallyInferred(dung.Preferred, dung.Arg(arg)))
-	} else if *problemFlag == "EE-PR" {
-		extensions = af.PreferredExtensions()
-		printExtensions(extensions)
-	} else if *problemFlag == "SE-PR" {
-		E, ok := af.SomeExtension(dung.Preferred)
-		printExtension(E, ok)
-
-		// Complete Semantics
-	} else if *problemFlag == "DC-CO" {
-		checkArgFlag()
-		printBool(af.CredulouslyInferred(dung.Complete, dung.Arg(arg)))
-	} else if *problemFlag == "DS-CO" {
-		checkArgFlag()
-		printBool(af.SkepticallyInferred(dung.Complete, dung.Arg(arg)))
-	} else if *problemFlag == "EE-CO" {
-		extensions = af.CompleteExtensions()
-		printExtensions(extensions)
-	} else if *problemFlag == "SE-CO" {
-		E, ok := af.SomeExtension(dung.Complete)
-		printExtension(E, ok)
+	// Problems have the form TASK-SEMANTICS, e.g. DC-GR
+	task, abbreviation := *problemFlag, ""
+	if i := strings.Index(*problemFlag, "-"); i >= 0 {
+		task, abbreviation = (*problemFlag)[:i], (*problemFlag)[i+1:]
+	}
+	semantics, ok := dung.ParseSemantics(abbreviation)
+	if !ok {
+		log.Fatal(fmt.Errorf("unsupported problem: %s\n", *problemFlag))
+		return
+	}
 
-		// Stable Semantics
-	} else if *problemFlag == "DC-ST" {
+	switch task {
+	case "DC":
+		// YES followed by an extension containing the argument, or NO
 		checkArgFlag()
-		printBool(af.CredulouslyInferred(dung.Stable, dung.Arg(arg)))
-	} else if *problemFlag == "DS-ST" {
+		E, ok := af.CredulousWitness(semantics, argument(arg))
+		if ok {
+			extensions = []dung.ArgSet{E}
+			fmt.Printf("YES\n")
+			printWitness(E)
+		} else {
+			fmt.Printf("NO\n")
+		}
+	case "DS":
+		// YES, or NO followed by an extension not containing the argument
 		checkArgFlag()
-		printBool(af.SkepticallyInferred(dung.Stable, dung.Arg(arg)))
-	} else if *problemFlag == "EE-ST" {
-		extensions = af.StableExtensions()
-		printExtensions(extensions)
-	} else if *problemFlag == "SE-ST" {
-		E, ok := af.SomeExtension(dung.Stable)
-		printExtension(E, ok)
-	} else if *problemFlag == "traverse" {
-		af.Traverse(func(E dung.ArgSet) {
-			fmt.Printf("%v\n", E)
+		E, ok := af.SkepticalCounterexample(semantics, argument(arg))
+		if ok {
+			extensions = []dung.ArgSet{E}
+			fmt.Printf("NO\n")
+			printWitness(E)
+		} else {
+			fmt.Printf("YES\n")
+		}
+	case "EE":
+		// print the extensions as they are found
+		ctx := context.Background()
+		if *timeoutFlag > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, *timeoutFlag)
+			defer cancel()
+		}
+		extensions = []dung.ArgSet{}
+		err = af.EnumerateExtensions(ctx, semantics, *limitFlag, func(E dung.ArgSet) bool {
+			printWitness(E)
+			if *outputFlag != "" {
+				extensions = append(extensions, E)
+			}
+			return false
 		})
-	} else {
+		if err != nil {
+			log.Fatal(fmt.Errorf("enumeration stopped after %v: %s\n", *timeoutFlag, err))
+		}
+	case "SE":
+		E, ok := af.SomeExtension(semantics)
+		if ok {
+			extensions = []dung.ArgSet{E}
+		}
+		printExtension(E, ok)
+	case "CE":
+		// the number of extensions
+		fmt.Printf("%d\n", af.CountExtensions(semantics))
+	case "VE":
+		// YES if the set of arguments is an extension, or NO
+		S := dung.NewArgSet()
+		for _, id := range strings.Split(*setFlag, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				S[argument(id)] = true
+			}
+		}
+		if af.IsExtension(semantics, S) {
+			fmt.Printf("YES\n")
+		} else {
+			fmt.Printf("NO\n")
+		}
+	default:
 		log.Fatal(fmt.Errorf("unsupported problem: %s\n", *problemFlag))
 		return
 	}
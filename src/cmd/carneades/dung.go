@@ -7,67 +7,217 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"github.com/carneades/carneades-4/src/engine/dung"
+	"github.com/carneades/carneades-4/src/engine/dung/encoding/apx"
+	"github.com/carneades/carneades-4/src/engine/dung/encoding/dot"
 	"github.com/carneades/carneades-4/src/engine/dung/encoding/graphml"
 	"github.com/carneades/carneades-4/src/engine/dung/encoding/tgf"
+	"github.com/carneades/carneades-4/src/engine/dung/gen"
 	"log"
+	"math/rand"
 	"os"
 	"path/filepath"
 	"strings"
 )
 
 const helpDung = `
-usage: carneades dung [-f input-format] [-p problem] [-s semantics] [-a argument] [-o output-directory] [input-file]
+usage: carneades dung [-f input-format] [-p problem] [-s semantics] [-a argument] [-e arguments] [-labels] [-samples samples] [-seed seed] [-explain format] [-solver solver] [-o output-directory] [input-file]
 
 Evaluates a Dung abstract argumentation framework and prints its extensions
 to stdout and, optionally, to a directory of graphml files for visualizing the extensions.
 
 If no input-file is specified, input is read from stdin. 
 
-The -f flag ("from") specifies the format of the input file. Currently only 
-the Trivial Graph Format (tgf) is supported.  (default: tgf)
+The -f flag ("from") specifies the format of the input file, which must be
+one of tgf, for the Trivial Graph Format, or apx, for the ASPARTIX format.
+(default: tgf)
 
 The -p flag specifies the decision problem to be solved, which must be one
-of DC, DS, EE or SE, where
+of DC, DS, EE, SE, CE, VE, PC or PS, where
 
 - DC: Decide whether the given argument is credulously inferred.
 - DS: Decide whether the given argument is skeptically inferred.
 - EE: Enumerate all extensions of the framework.
 - SE: Show one extension of the framework, if any exist, or print "NO" if the
       framework has no extensions.
+- CE: Count the extensions of the framework.
+- VE: Verify whether the given set of arguments is an extension.
+- PC: Compute the probability that the given argument is credulously inferred.
+- PS: Compute the probability that the given argument is skeptically inferred.
 
 The default problem is EE, enumerating all extensions.
 
 The -s flag specifies the Dung semantics to use, which must be one of
-GR, SO, PR, ST, where
+GR, CO, PR, ST, SST, STG, ID, EG, NA, AD, CF2 or STG2, where
 
 - GR: Grounded semantics
 - CO: Complete semantics
 - PR: Preferred semantics
 - ST: Stable semantics
+- SST: Semi-stable semantics
+- STG: Stage semantics
+- ID: Ideal semantics
+- EG: Eager semantics
+- NA: Naive semantics
+- AD: Admissible sets
+- CF2: CF2 semantics
+- STG2: Stage2 semantics
 
 The default is GR, grounded semantics.
 
-The -a flag specifies the argument to check when solving DC and DS problems.
+Alternatively, the -s flag may specify a gradual semantics, which
+assigns a degree of acceptability between 0 and 1 to each argument,
+and must be one of hcat, max, card or count, where
+
+- hcat: h-categorizer semantics
+- max: max-based semantics
+- card: card-based semantics
+- count: counting semantics
+
+Using a gradual semantics, the -p flag is ignored and the arguments are
+printed ranked by their degrees, most acceptable first, one per line.
+If the -o flag is specified, the degrees are written to a graphml file,
+degrees.graphml, with nodes shaded by degree.
+
+The -a flag specifies the argument to check when solving DC, DS, PC and PS problems.
 It should be the id of the argument in the input file.  default: none.
 
+The -e flag specifies the set of arguments to verify when solving VE problems,
+as a comma-separated list of the ids of the arguments, e.g. a,b,c. The empty
+set is specified with -e "". default: none.
+
+For PC and PS problems, the framework is a probabilistic argumentation
+framework, in which the arguments and attacks are present with independent
+probabilities, and the probability is the sum of the probabilities of the
+frameworks induced by the present arguments and attacks in which the argument
+is inferred. In tgf files, the probabilities are the labels of the nodes and
+edges, as in "a 0.7" and "a b 0.5". In apx files, they are added to the facts,
+as in arg(a,0.7). and att(a,b,0.5). Arguments and attacks without a
+probability have probability 1.
+
+The -samples flag specifies the number of frameworks to sample to estimate
+the probability of PC and PS problems, using the Monte-Carlo method. If it is
+0, the probability is computed exactly, by enumerating the frameworks, which
+is only feasible if few arguments and attacks are uncertain. (default: 0)
+
+The -seed flag specifies the seed of the random number generator used to
+sample frameworks, so that estimates can be reproduced. (default: 1)
+
+The -labels flag prints the labellings of the extensions of EE and SE
+problems, instead of the extensions, one per line. A labelling lists the
+arguments labelled in, i.e. the members of the extension, out, i.e. the
+arguments attacked by some member of the extension, and undecided. default: false.
+
+The -explain flag prints an explanation of the answer to a DC or DS problem,
+after the answer, in the given format, which must be one of text, json or dot.
+For grounded semantics, and for DC problems using admissible, complete or
+preferred semantics, the explanation of an inferred argument includes a
+dispute tree, showing how every attack on the argument can be countered.
+Otherwise the explanation shows an extension containing the argument, for
+DC problems, or an extension not containing the argument, for DS problems,
+if there is one. default: none.
+
+The -solver flag selects the algorithm used to compute extensions, which must
+be one of backtracking, scc or subsets, where
+
+- backtracking: Labelling-based backtracking search.
+- scc: Backtracking search, one strongly connected component at a time.
+  Used for grounded, complete, preferred and stable semantics.
+- subsets: Enumeration of all subsets of the arguments. Only feasible for 
+  small frameworks. Intended as a reference for testing.
+
+The default is backtracking.
+
 If the -o flag is specified, graphml files are written to the given directory.
 The yEd Graphml editor can be used to view the evaluated argumentation framework.
 Existing directories will not be overwritten or modified. A file for each 
-extension of the argumentation framework will be created.
+extension of the argumentation framework will be created. The arguments are
+filled green if they are labelled in, red if out and yellow if undecided.
+
+The gen subcommand generates an argumentation framework, for benchmarking:
+` + helpDungGen
+
+const helpDungGen = `
+usage: carneades dung gen [-m model] [-n arguments] [-p probability] [-k degree] [-cols columns] [-seed seed] [-f output-format] [-o output-file]
+
+Generates a random or structured Dung abstract argumentation framework and
+writes it to stdout or, if the -o flag is specified, to the given file.
+
+The -m flag specifies the model of the framework, which must be one of
+er, ba, grid or ws, where
+
+- er: Erdős–Rényi framework, in which each argument attacks each other
+  argument with the probability of the -p flag.
+- ba: Barabási–Albert framework, in which each argument is connected to
+  k preceding arguments, chosen by preferential attachment.
+- grid: Grid framework, in which the arguments form a grid with the number
+  of columns of the -cols flag, and each argument is connected to its
+  horizontal and vertical neighbours.
+- ws: Watts–Strogatz framework, in which the arguments form a ring, each
+  argument is connected to its k nearest neighbours, and each connection
+  is rewired with the probability of the -p flag.
+
+Except for the er model, each connection between two arguments is an attack
+in one direction, chosen at random. The default model is er.
+
+The -n flag specifies the number of arguments. (default: 10)
+
+The -p flag specifies the probability of attacks, for the er model, or of
+rewiring connections, for the ws model. (default: 0.1)
+
+The -k flag specifies the number of connections of each argument, for the ba
+and ws models. It must be even for the ws model. (default: 2)
+
+The -cols flag specifies the number of columns of the grid, which must not
+exceed n. The number of rows is n divided by the number of columns, rounded
+down. (default: 5)
+
+The -seed flag specifies the seed of the random number generator, so that
+frameworks can be reproduced. (default: 1)
+
+The -f flag specifies the format of the output, which must be one of tgf
+or apx. (default: tgf)
 `
 
-const formats = "[tgf]"
-const problems = "[DC-GR,DS-GR,EE-GR,SE-GR,DC-PR,DS-PR,EE-PR,SE-PR,DC-CO,DS-CO,EE-CO,SE-CO,DC-ST,DS-ST,EE-ST,SE-ST]"
+const formats = "[tgf,apx]"
+
+var gradualSemanticsAbbreviations = map[string]dung.GradualSemantics{
+	"hcat":  dung.HCategorizer,
+	"max":   dung.MaxBased,
+	"card":  dung.CardBased,
+	"count": dung.Counting,
+}
+
+// Parses a comma-separated list of argument ids
+func parseArgSet(s string) dung.ArgSet {
+	S := dung.NewArgSet()
+	for _, id := range strings.Split(s, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			S[dung.Arg(id)] = true
+		}
+	}
+	return S
+}
 
 func dungCmd() {
+	if len(os.Args) > 2 && os.Args[2] == "gen" {
+		dungGenCmd()
+		return
+	}
 	dungFlags := flag.NewFlagSet("dung", flag.ContinueOnError)
 	problemFlag := dungFlags.String("p", "EE", "the problem to solve")
 	semanticsFlag := dungFlags.String("s", "GR", "the semantics to use")
 	formatFlag := dungFlags.String("f", "tgf", "the format of the source file")
 	argFlag := dungFlags.String("a", "", "the id of the argument to check")
+	setFlag := dungFlags.String("e", "", "the comma-separated ids of the arguments of the set to verify")
+	samplesFlag := dungFlags.Int("samples", 0, "the number of frameworks to sample for PC and PS problems, or 0 to compute probabilities exactly")
+	seedFlag := dungFlags.Int64("seed", 1, "the seed of the random number generator used to sample frameworks")
+	labelsFlag := dungFlags.Bool("labels", false, "print the labellings of the extensions of EE and SE problems")
+	explainFlag := dungFlags.String("explain", "", "the format of explanations of DC and DS answers [text,json,dot]")
+	solverFlag := dungFlags.String("solver", "backtracking", "the solver to use")
 	outputFlag := dungFlags.String("o", "", "the name of a new directory to create for outputting GraphML files")
 
 	if err := dungFlags.Parse(os.Args[2:]); err != nil {
@@ -82,7 +232,7 @@ func dungCmd() {
 		}
 	}
 
-	if *formatFlag != "tgf" {
+	if *formatFlag != "tgf" && *formatFlag != "apx" {
 		log.Fatal(fmt.Errorf("unsupported format: %s\n", *formatFlag))
 		return
 	}
@@ -105,11 +255,38 @@ func dungCmd() {
 		return
 	}
 
-	if *formatFlag == "tgf" {
+	var paf dung.PAF
+	probabilistic := *problemFlag == "PC" || *problemFlag == "PS"
+
+	switch {
+	case *formatFlag == "tgf" && probabilistic:
+		paf, err = tgf.ImportPAF(inFile)
+		af = paf.AF()
+	case *formatFlag == "tgf":
 		af, err = tgf.Import(inFile)
-		if err != nil {
-			log.Fatal(err)
-		}
+	case *formatFlag == "apx" && probabilistic:
+		paf, err = apx.ImportPAF(inFile)
+		af = paf.AF()
+	case *formatFlag == "apx":
+		af, err = apx.Import(inFile)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	switch *solverFlag {
+	case "backtracking":
+		af.SetSolver(dung.BacktrackingSolver)
+	case "subsets":
+		af.SetSolver(dung.SubsetSolver)
+	case "scc":
+		af.SetSolver(dung.SCCSolver)
+	default:
+		log.Fatal(fmt.Errorf("unsupported solver: %s\n", *solverFlag))
+		return
+	}
+	if probabilistic {
+		paf.SetSolver(af.Solver())
 	}
 
 	printExtensions := func(extensions []dung.ArgSet) {
@@ -128,6 +305,12 @@ func dungCmd() {
 		}
 	}
 
+	printLabellings := func(extensions []dung.ArgSet) {
+		for _, E := range extensions {
+			fmt.Printf("%s\n", af.ExtensionLabelling(E))
+		}
+	}
+
 	printBool := func(b bool) {
 		if b {
 			fmt.Printf("YES\n")
@@ -136,71 +319,113 @@ func dungCmd() {
 		}
 	}
 
-	problem := *problemFlag + "-" + *semanticsFlag
+	if g, ok := gradualSemanticsAbbreviations[*semanticsFlag]; ok {
+		degrees := af.Degrees(g)
+		for _, class := range dung.Rank(degrees) {
+			for _, arg := range class {
+				fmt.Printf("%s %.6f\n", arg, degrees[arg])
+			}
+		}
+		if *outputFlag != "" {
+			if _, err := os.Stat(*outputFlag); err == nil {
+				log.Fatal(fmt.Errorf("The output directory, %s, should not already exist\n", *outputFlag))
+				return
+			}
+			if err = os.MkdirAll(*outputFlag, 0755); err != nil {
+				log.Fatal(fmt.Errorf("%s\n", err))
+				return
+			}
+			f, err := os.Create(filepath.Join(*outputFlag, "degrees.graphml"))
+			if err != nil {
+				log.Fatal(fmt.Errorf("%s\n", err))
+				return
+			}
+			graphml.ExportDegrees(f, af, degrees)
+			f.Close()
+		}
+		return
+	}
 
-	// Grounded Semantics
-	if problem == "DC-GR" {
-		checkArgFlag()
-		printBool(af.CredulouslyInferred(dung.Grounded, dung.Arg(arg)))
-	} else if problem == "DS-GR" {
-		checkArgFlag()
-		printBool(af.SkepticallyInferred(dung.Grounded, dung.Arg(arg)))
-	} else if problem == "EE-GR" {
-		E := af.GroundedExtension()
-		extensions = []dung.ArgSet{E}
-		fmt.Printf("[%s]\n", E)
-	} else if problem == "SE-GR" {
-		E := af.GroundedExtension()
-		extensions = []dung.ArgSet{E}
-		fmt.Printf("%s\n", E)
-
-		// Preferred Semantics
-	} else if problem == "DC-PR" {
-		checkArgFlag()
-		printBool(af.CredulouslyInferred(dung.Preferred, dung.Arg(arg)))
-	} else if problem == "DS-PR" {
-		checkArgFlag()
-		printBool(af.SkepticallyInferred(dung.Preferred, dung.Arg(arg)))
-	} else if problem == "EE-PR" {
-		extensions = af.PreferredExtensions()
-		printExtensions(extensions)
-	} else if problem == "SE-PR" {
-		E, ok := af.SomeExtension(dung.Preferred)
-		printExtension(E, ok)
-
-		// Complete Semantics
-	} else if problem == "DC-CO" {
-		checkArgFlag()
-		printBool(af.CredulouslyInferred(dung.Complete, dung.Arg(arg)))
-	} else if problem == "DS-CO" {
+	semantics, ok := dung.ParseSemantics(*semanticsFlag)
+	if !ok {
+		log.Fatal(fmt.Errorf("unsupported semantics: %s\n", *semanticsFlag))
+		return
+	}
+
+	printExplanation := func(e dung.Explanation) {
+		switch *explainFlag {
+		case "text":
+			e.WriteText(os.Stdout)
+		case "json":
+			b, err := json.MarshalIndent(e, "", "  ")
+			if err != nil {
+				log.Fatal(err)
+			}
+			fmt.Printf("%s\n", b)
+		case "dot":
+			if err := dot.ExportExplanation(os.Stdout, af, e); err != nil {
+				log.Fatal(err)
+			}
+		default:
+			log.Fatal(fmt.Errorf("unsupported explanation format: %s\n", *explainFlag))
+		}
+	}
+
+	switch *problemFlag {
+	case "DC":
 		checkArgFlag()
-		printBool(af.SkepticallyInferred(dung.Complete, dung.Arg(arg)))
-	} else if problem == "EE-CO" {
-		extensions = af.CompleteExtensions()
-		printExtensions(extensions)
-	} else if problem == "SE-CO" {
-		E, ok := af.SomeExtension(dung.Complete)
-		printExtension(E, ok)
-
-		// Stable Semantics
-	} else if problem == "DC-ST" {
+		if *explainFlag == "" {
+			printBool(af.CredulouslyInferred(semantics, dung.Arg(arg)))
+			break
+		}
+		e := af.ExplainCredulous(semantics, dung.Arg(arg))
+		printBool(e.Accepted)
+		printExplanation(e)
+	case "DS":
 		checkArgFlag()
-		printBool(af.CredulouslyInferred(dung.Stable, dung.Arg(arg)))
-	} else if problem == "DS-ST" {
+		if *explainFlag == "" {
+			printBool(af.SkepticallyInferred(semantics, dung.Arg(arg)))
+			break
+		}
+		e := af.ExplainSkeptical(semantics, dung.Arg(arg))
+		printBool(e.Accepted)
+		printExplanation(e)
+	case "EE":
+		extensions = af.Extensions(semantics)
+		if *labelsFlag {
+			printLabellings(extensions)
+		} else {
+			printExtensions(extensions)
+		}
+	case "SE":
+		E, ok := af.SomeExtension(semantics)
+		if ok {
+			extensions = []dung.ArgSet{E}
+		}
+		if *labelsFlag && ok {
+			printLabellings(extensions)
+		} else {
+			printExtension(E, ok)
+		}
+	case "CE":
+		fmt.Printf("%d\n", af.CountExtensions(semantics))
+	case "VE":
+		printBool(af.IsExtension(semantics, parseArgSet(*setFlag)))
+	case "PC", "PS":
 		checkArgFlag()
-		printBool(af.SkepticallyInferred(dung.Stable, dung.Arg(arg)))
-	} else if problem == "EE-ST" {
-		extensions = af.StableExtensions()
-		printExtensions(extensions)
-	} else if problem == "SE-ST" {
-		E, ok := af.SomeExtension(dung.Stable)
-		printExtension(E, ok)
-	} else if problem == "traverse" {
+		skeptical := *problemFlag == "PS"
+		if *samplesFlag > 0 {
+			r := rand.New(rand.NewSource(*seedFlag))
+			fmt.Printf("%.6f\n", paf.SampledProbability(r, *samplesFlag, semantics, dung.Arg(arg), skeptical))
+		} else {
+			fmt.Printf("%.6f\n", paf.ExactProbability(semantics, dung.Arg(arg), skeptical))
+		}
+	case "traverse":
 		af.Traverse(func(E dung.ArgSet) {
 			fmt.Printf("%v\n", E)
 		})
-	} else {
-		log.Fatal(fmt.Errorf("unsupported problem: %s\n", problem))
+	default:
+		log.Fatal(fmt.Errorf("unsupported problem: %s-%s\n", *problemFlag, *semanticsFlag))
 		return
 	}
 	if *outputFlag != "" && extensions != nil {
@@ -224,3 +449,73 @@ func dungCmd() {
 		}
 	}
 }
+
+func dungGenCmd() {
+	genFlags := flag.NewFlagSet("dung gen", flag.ContinueOnError)
+	modelFlag := genFlags.String("m", "er", "the model of the framework [er,ba,grid,ws]")
+	nFlag := genFlags.Int("n", 10, "the number of arguments")
+	pFlag := genFlags.Float64("p", 0.1, "the probability of attacks (er) or rewiring (ws)")
+	kFlag := genFlags.Int("k", 2, "the number of connections of each argument (ba, ws)")
+	colsFlag := genFlags.Int("cols", 5, "the number of columns of the grid")
+	seedFlag := genFlags.Int64("seed", 1, "the seed of the random number generator")
+	formatFlag := genFlags.String("f", "tgf", "the format of the output "+formats)
+	outputFlag := genFlags.String("o", "", "the name of the output file")
+
+	if err := genFlags.Parse(os.Args[3:]); err != nil {
+		log.Fatal(err)
+	}
+
+	if *nFlag < 0 {
+		log.Fatal(fmt.Errorf("the number of arguments should not be negative: %d\n", *nFlag))
+	}
+	if *kFlag < 0 {
+		log.Fatal(fmt.Errorf("the number of connections should not be negative: %d\n", *kFlag))
+	}
+
+	r := rand.New(rand.NewSource(*seedFlag))
+	var af dung.AF
+	switch *modelFlag {
+	case "er":
+		af = gen.ErdosRenyi(r, *nFlag, *pFlag)
+	case "ba":
+		af = gen.BarabasiAlbert(r, *nFlag, *kFlag)
+	case "grid":
+		if *colsFlag <= 0 {
+			log.Fatal(fmt.Errorf("the number of columns should be positive: %d\n", *colsFlag))
+		}
+		if *nFlag < *colsFlag {
+			log.Fatal(fmt.Errorf("the number of arguments should be at least the number of columns: %d\n", *nFlag))
+		}
+		af = gen.Grid(r, *nFlag / *colsFlag, *colsFlag)
+	case "ws":
+		if *kFlag%2 != 0 || *kFlag >= *nFlag {
+			log.Fatal(fmt.Errorf("k should be even and less than n: %d\n", *kFlag))
+		}
+		af = gen.WattsStrogatz(r, *nFlag, *kFlag, *pFlag)
+	default:
+		log.Fatal(fmt.Errorf("unsupported model: %s\n", *modelFlag))
+	}
+
+	outFile := os.Stdout
+	if *outputFlag != "" {
+		f, err := os.Create(*outputFlag)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		outFile = f
+	}
+
+	var err error
+	switch *formatFlag {
+	case "tgf":
+		err = tgf.Export(outFile, af)
+	case "apx":
+		err = apx.Export(outFile, af)
+	default:
+		log.Fatal(fmt.Errorf("unsupported format: %s\n", *formatFlag))
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -14,11 +14,13 @@ import (
 	"github.com/carneades/carneades-4/src/engine/caes/encoding/graphml"
 	"github.com/carneades/carneades-4/src/engine/caes/encoding/lkif"
 	"github.com/carneades/carneades-4/src/engine/caes/encoding/yaml"
+	"github.com/carneades/carneades-4/src/engine/terms"
 	"github.com/carneades/carneades-4/src/engine/validation"
 )
 
 const helpEval = `
 usage: carneades eval [-f input-format] [-t output-format] [-o output-file] [input-file]
+       carneades eval [-f input-format] -sensitivity statement [-max n] [-o output-file] [input-file]
 
 Evaluates an argument graph and prints the result in the selected output format.
 The argument graph is first checked for syntactic and semantic errors and
@@ -83,8 +85,24 @@ visualization project. For further information see:
 
 	http://graphviz.org/
 
+In the graphml and dot formats, each statement is annotated with an
+explanation of its label, as the description of its node in graphml
+and as the tooltip of its node in dot. See "carneades help explain".
+
 The -o flag specifies the output file name. If the -o flag is not used, 
 output goes to stdout.
+
+The -sensitivity flag selects a sensitivity analysis of the statement with
+the given id, instead of printing the evaluated argument graph. The label
+of the statement is printed, followed by the minimal changes of the
+assumptions which change the label, one per line, e.g.
+
+    out: retract q; assume r
+
+meaning that the statement is out if q is no longer assumed and r is
+assumed. Only statements on which the label of the statement depends
+are retracted or assumed, and only changes of at most -max assumptions
+are considered. (default: 2) The analysis is exponential in -max.
 `
 
 func evalCmd() {
@@ -92,6 +110,8 @@ func evalCmd() {
 	fromFlag := eval.String("f", "yaml", "the format of the source file")
 	toFlag := eval.String("t", "graphml", "the format of the output file")
 	outFileFlag := eval.String("o", "", "the filename of the output file")
+	sensitivityFlag := eval.String("sensitivity", "", "the statement of a sensitivity analysis")
+	maxFlag := eval.Int("max", 2, "the maximum number of assumptions changed in a sensitivity analysis")
 
 	var inFile *os.File
 	var outFile *os.File
@@ -191,9 +211,27 @@ func evalCmd() {
 		// derive further arguments
 		ag.Infer()
 
+		if *sensitivityFlag != "" {
+			changes, err := ag.Sensitivity(*sensitivityFlag, *maxFlag)
+			if err != nil {
+				log.Fatal(fmt.Errorf("%s\n", err))
+				return
+			}
+			l := ag.GroundedLabelling()
+			s := ag.Statements[terms.Normalize(*sensitivityFlag)]
+			fmt.Fprintf(outFile, "statement: %s\n", s.Id)
+			fmt.Fprintf(outFile, "label: %s\n", l[s])
+			fmt.Fprintf(outFile, "changes:\n")
+			for _, c := range changes {
+				fmt.Fprintf(outFile, "    %s\n", c)
+			}
+			outFile.Close()
+			return
+		}
+
 		// evaluate the argument graph, using grounded semantics
 		// and update the labels of the statements in the argument graph
-		l := ag.GroundedLabelling()
+		l, ex := ag.ExplainedGroundedLabelling()
 		// fmt.Printf("labelling=%v\n", l)
 		ag.ApplyLabelling(l)
 
@@ -202,14 +240,14 @@ func evalCmd() {
 			yaml.Export(outFile, ag)
 			outFile.Close()
 		case "graphml":
-			err = graphml.Export(outFile, ag)
+			err = graphml.ExportExplanations(outFile, ag, ex)
 			outFile.Close()
 			if err != nil {
 				log.Fatal(err)
 				return
 			}
 		case "dot":
-			err = dot.Export(outFile, ag)
+			err = dot.ExportExplanations(outFile, ag, ex)
 			outFile.Close()
 			if err != nil {
 				log.Fatal(err)
@@ -0,0 +1,130 @@
+// Copyright © 2015 The Carneades Authors
+// This Source Code Form is subject to the terms of the
+// Mozilla Public License, v. 2.0. If a copy of the MPL
+// was not distributed with this file, You can obtain one
+// at http://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/carneades/carneades-4/src/engine/caes"
+	"github.com/carneades/carneades-4/src/engine/caes/encoding/agxml"
+	"github.com/carneades/carneades-4/src/engine/caes/encoding/aif"
+	"github.com/carneades/carneades-4/src/engine/caes/encoding/caf"
+	"github.com/carneades/carneades-4/src/engine/caes/encoding/lkif"
+	"github.com/carneades/carneades-4/src/engine/caes/encoding/yaml"
+	"github.com/carneades/carneades-4/src/engine/terms"
+	"github.com/carneades/carneades-4/src/engine/validation"
+)
+
+const helpExplain = `
+usage: carneades explain [-f input-format] statement [input-file]
+
+Evaluates an argument graph, as the eval command, and explains why the
+statement with the given id is in, out or undecided, by printing the rule
+of the evaluation which fixed its label:
+
+    assumed - the statement is assumed, and thus in
+    alternative assumed - another position of the issue of the statement
+        is assumed, so the statement is out
+    unsupported - the statement has no applicable argument with a weight
+        greater than 0.0, and is out
+    supported non-issue - the statement is not at issue and has an
+        applicable argument with a weight greater than 0.0, and is in
+    issue resolved - the issue of the statement was resolved by applying
+        its proof standard to the maximum weights of the arguments pro
+        each of its positions
+    no rule applied - the statement remains undecided
+
+The weights of the arguments taken into account and the labels of their
+undercutters are printed too. The step is the iteration of the evaluation
+in which the rule was applied, where step 0 is the initialization with
+the assumptions.
+
+If no input-file is specified, input is read from stdin.
+
+The -f flag ("from") specifies the format of the input file: yaml, aif,
+agxml, lkif or caf. (default: yaml) See "carneades help eval" for
+further information about these formats.
+`
+
+func explainCmd() {
+	explain := flag.NewFlagSet("explain", flag.ContinueOnError)
+	fromFlag := explain.String("f", "yaml", "the format of the source file")
+
+	var inFile *os.File
+	var err error
+
+	if err := explain.Parse(os.Args[2:]); err != nil {
+		log.Fatal(err)
+	}
+
+	if !contains(inputFormats, *fromFlag) {
+		log.Fatal(fmt.Errorf("unsupported input format: %s\n", *fromFlag))
+		return
+	}
+	switch explain.NArg() {
+	case 1:
+		inFile = os.Stdin
+	case 2:
+		inFile, err = os.Open(explain.Args()[1])
+		if err != nil {
+			log.Fatal(err)
+		}
+	default:
+		log.Fatal(fmt.Errorf("incorrect number of arguments after the command flags; should be 1, the statement, to read from stdin, or 2, the statement and the input file\n"))
+		return
+	}
+	id := explain.Args()[0]
+
+	var ag *caes.ArgGraph
+
+	switch *fromFlag {
+	case "yaml":
+		ag, err = yaml.Import(inFile)
+	case "agxml":
+		ag, err = agxml.Import(inFile)
+	case "aif":
+		ag, err = aif.Import(inFile)
+	case "lkif":
+		ag, err = lkif.Import(inFile)
+	case "caf":
+		ag, err = caf.Import(inFile)
+	default:
+		log.Fatal(fmt.Errorf("unknown or unsupported input format: %s\n", *fromFlag))
+		return
+	}
+	inFile.Close()
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	// Validate the argument graph
+	problems := validation.Validate(ag)
+	for _, p := range problems {
+		if p.Expression == "" {
+			fmt.Fprintf(os.Stderr, "%s: %s: %s\n", p.Category, p.Id, p.Description)
+		} else {
+			fmt.Fprintf(os.Stderr, "%s: %s: %s: %s\n", p.Category, p.Id, p.Description, p.Expression)
+		}
+	}
+	if len(problems) > 0 {
+		os.Exit(1)
+	}
+
+	ag.Infer()
+	_, ex := ag.ExplainedGroundedLabelling()
+	// the ids of the statements have been normalized by the evaluation
+	stmt, ok := ag.Statements[terms.Normalize(id)]
+	if !ok {
+		log.Fatal(fmt.Errorf("unknown statement: %s\n", id))
+		return
+	}
+	ex[stmt].WriteText(os.Stdout)
+}
@@ -0,0 +1,146 @@
+// Copyright © 2015 The Carneades Authors
+// This Source Code Form is subject to the terms of the
+// Mozilla Public License, v. 2.0. If a copy of the MPL
+// was not distributed with this file, You can obtain one
+// at http://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/carneades/carneades-4/src/engine/caes"
+	"github.com/carneades/carneades-4/src/engine/caes/encoding/agxml"
+	"github.com/carneades/carneades-4/src/engine/caes/encoding/aif"
+	"github.com/carneades/carneades-4/src/engine/caes/encoding/caf"
+	"github.com/carneades/carneades-4/src/engine/caes/encoding/lkif"
+	"github.com/carneades/carneades-4/src/engine/caes/encoding/yaml"
+	"github.com/carneades/carneades-4/src/engine/validation"
+)
+
+const helpGoals = `
+usage: carneades goals [-f input-format] [-l label] [-max n] statement [input-file]
+
+Selects goals using abduction: computes the minimal sets of statements
+which, if accepted or rejected, would make the given statement in or
+out, to help decide which evidence to gather next. The sets are printed
+one per line, ranked by size, e.g.
+
+    accept q; reject r
+
+The argument graph is evaluated as by the eval command, taking the
+accepted and rejected statements into account, so issues and proof
+standards are respected. Only statements on which the label of the given
+statement depends are considered. If the statement already has the label,
+or no sets are found, this is reported instead.
+
+If no input-file is specified, input is read from stdin.
+
+The -f flag ("from") specifies the format of the input file: yaml, aif,
+agxml, lkif or caf. (default: yaml) See "carneades help eval" for
+further information about these formats.
+
+The -l flag specifies the label of the statement to be achieved: in or
+out. (default: in)
+
+The -max flag specifies the maximum size of the sets. (default: 2)
+The computation is exponential in this size.
+`
+
+func goalsCmd() {
+	goals := flag.NewFlagSet("goals", flag.ContinueOnError)
+	fromFlag := goals.String("f", "yaml", "the format of the source file")
+	labelFlag := goals.String("l", "in", "the label of the statement to be achieved: in or out")
+	maxFlag := goals.Int("max", 2, "the maximum number of statements accepted or rejected")
+
+	var inFile *os.File
+	var err error
+
+	if err := goals.Parse(os.Args[2:]); err != nil {
+		log.Fatal(err)
+	}
+
+	if !contains(inputFormats, *fromFlag) {
+		log.Fatal(fmt.Errorf("unsupported input format: %s\n", *fromFlag))
+		return
+	}
+	var label caes.Label
+	switch *labelFlag {
+	case "in":
+		label = caes.In
+	case "out":
+		label = caes.Out
+	default:
+		log.Fatal(fmt.Errorf("unsupported label: %s; should be in or out\n", *labelFlag))
+		return
+	}
+	switch goals.NArg() {
+	case 1:
+		inFile = os.Stdin
+	case 2:
+		inFile, err = os.Open(goals.Args()[1])
+		if err != nil {
+			log.Fatal(err)
+		}
+	default:
+		log.Fatal(fmt.Errorf("incorrect number of arguments after the command flags; should be 1, the statement, to read from stdin, or 2, the statement and the input file\n"))
+		return
+	}
+	id := goals.Args()[0]
+
+	var ag *caes.ArgGraph
+
+	switch *fromFlag {
+	case "yaml":
+		ag, err = yaml.Import(inFile)
+	case "agxml":
+		ag, err = agxml.Import(inFile)
+	case "aif":
+		ag, err = aif.Import(inFile)
+	case "lkif":
+		ag, err = lkif.Import(inFile)
+	case "caf":
+		ag, err = caf.Import(inFile)
+	default:
+		log.Fatal(fmt.Errorf("unknown or unsupported input format: %s\n", *fromFlag))
+		return
+	}
+	inFile.Close()
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	// Validate the argument graph
+	problems := validation.Validate(ag)
+	for _, p := range problems {
+		if p.Expression == "" {
+			fmt.Fprintf(os.Stderr, "%s: %s: %s\n", p.Category, p.Id, p.Description)
+		} else {
+			fmt.Fprintf(os.Stderr, "%s: %s: %s: %s\n", p.Category, p.Id, p.Description, p.Expression)
+		}
+	}
+	if len(problems) > 0 {
+		os.Exit(1)
+	}
+
+	ag.Infer()
+	sets, err := ag.Abduce(id, label, *maxFlag)
+	if err != nil {
+		log.Fatal(fmt.Errorf("%s\n", err))
+		return
+	}
+	switch {
+	case len(sets) == 0:
+		fmt.Printf("no sets of at most %d statements found\n", *maxFlag)
+	case sets[0].Size() == 0:
+		fmt.Printf("the statement is already %s\n", label)
+	default:
+		for _, s := range sets {
+			fmt.Printf("%s\n", s)
+		}
+	}
+}
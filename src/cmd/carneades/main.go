@@ -34,6 +34,8 @@ The commands are:
 
 check - validate an structured argument graph and report any syntactic or semantic errors 
 eval - evaluate a structured argument graph
+explain - explain the label of a statement of an evaluated argument graph
+goals - select the statements to accept or reject to make a statement in or out
 dung - compute extensions of a Dung abstract argumentation framework
 server - start the Carneades web service
 help - displays instructions
@@ -50,6 +52,10 @@ func main() {
 			checkCmd()
 		case "eval":
 			evalCmd()
+		case "explain":
+			explainCmd()
+		case "goals":
+			goalsCmd()
 		case "dung":
 			dungCmd()
 		case "server":
@@ -63,6 +69,10 @@ func main() {
 					fmt.Printf("%s\n", helpCheck)
 				case "eval":
 					fmt.Printf("%s\n", helpEval)
+				case "explain":
+					fmt.Printf("%s\n", helpExplain)
+				case "goals":
+					fmt.Printf("%s\n", helpGoals)
 				case "dung":
 					fmt.Printf("%s\n", helpDung)
 				case "server":
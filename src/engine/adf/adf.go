@@ -0,0 +1,444 @@
+// Copyright © 2015 The Carneades Authors
+// This Source Code Form is subject to the terms of the
+// Mozilla Public License, v. 2.0. If a copy of the MPL
+// was not distributed with this file, You can obtain one
+// at http://mozilla.org/MPL/2.0/.
+
+// Abstract Dialectical Frameworks (ADFs), which generalize Dung AFs by
+// giving each argument, or statement, an acceptance condition, a Boolean
+// formula over its parents, instead of requiring all its attackers to be
+// out. The semantics are defined using three-valued interpretations,
+// represented as Dung labellings: in for true, out for false and
+// undecided for unknown.
+// See: Brewka, G. et al. Abstract dialectical frameworks revisited.
+// IJCAI 2013.
+
+package adf
+
+import (
+	"fmt"
+	"github.com/carneades/carneades-4/src/engine/dung"
+	"github.com/carneades/carneades-4/src/engine/terms"
+	"strconv"
+	"strings"
+)
+
+// An ADF. The acceptance conditions are terms of the terms package,
+// using the following connectives:
+//
+// - true, false
+// - !a, ¬a, not(a): negation
+// - a && b, and(a, b, ...): conjunction
+// - a || b, or(a, b, ...): disjunction
+// - imp(a, b): implication
+// - a == b, iff(a, b): equivalence
+// - a != b, xor(a, b): exclusive disjunction
+//
+// The atoms of the conditions are the arguments of the ADF, its parents.
+// Arguments whose names are not atoms of the terms package, e.g. names
+// with spaces, can only be used in ADFs constructed from terms, such as
+// the ADFs of AFs.
+type ADF struct {
+	args    []dung.Arg
+	conds   map[dung.Arg]terms.Term
+	parents map[dung.Arg][]dung.Arg
+}
+
+// Returns an ADF with the given arguments and acceptance conditions,
+// parsing the conditions. Arguments without a condition are
+// unconditionally accepted, i.e. their condition is true. An error is
+// returned if some condition cannot be parsed, uses an unsupported
+// connective or refers to an undeclared argument.
+func NewADF(args []dung.Arg, conds map[dung.Arg]string) (ADF, error) {
+	ts := make(map[dung.Arg]terms.Term)
+	for arg, cond := range conds {
+		t, err := ParseCondition(cond)
+		if err != nil {
+			return ADF{}, fmt.Errorf("acceptance condition of %s: %s", arg, err)
+		}
+		ts[arg] = t
+	}
+	return NewADFFromTerms(args, ts)
+}
+
+// Returns an ADF with the given arguments and acceptance conditions.
+// See NewADF.
+func NewADFFromTerms(args []dung.Arg, conds map[dung.Arg]terms.Term) (ADF, error) {
+	declared := dung.NewArgSet(args...)
+	adf := ADF{args: args, conds: make(map[dung.Arg]terms.Term), parents: make(map[dung.Arg][]dung.Arg)}
+	for arg, cond := range conds {
+		if !declared.Contains(arg) {
+			return ADF{}, fmt.Errorf("acceptance condition of an undeclared argument: %s", arg)
+		}
+		parents := dung.NewArgSet()
+		if err := collectParents(cond, parents); err != nil {
+			return ADF{}, fmt.Errorf("acceptance condition of %s: %s", arg, err)
+		}
+		for _, p := range args {
+			if parents.Contains(p) {
+				adf.parents[arg] = append(adf.parents[arg], p)
+				delete(parents, p)
+			}
+		}
+		for p := range parents {
+			return ADF{}, fmt.Errorf("acceptance condition of %s: undeclared argument %s", arg, p)
+		}
+		adf.conds[arg] = cond
+	}
+	for _, arg := range args {
+		if _, ok := adf.conds[arg]; !ok {
+			adf.conds[arg] = terms.Bool(true)
+		}
+	}
+	return adf, nil
+}
+
+// Parses an acceptance condition. See ADF for the connectives.
+func ParseCondition(s string) (terms.Term, error) {
+	t, ok := terms.ReadString(s)
+	if !ok {
+		return nil, fmt.Errorf("could not parse %s", s)
+	}
+	if err := collectParents(t, dung.NewArgSet()); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Returns the argument referred to by an atom of an acceptance
+// condition. The boolean value returned is false if the term is not an
+// atom.
+func argOf(t terms.Term) (dung.Arg, bool) {
+	switch v := t.(type) {
+	case terms.Atom:
+		return dung.Arg(v), true
+	case terms.Int:
+		return dung.Arg(strconv.Itoa(int(v))), true
+	case terms.String:
+		return dung.Arg(v), true
+	case terms.Variable:
+		return dung.Arg(v.Name), true
+	}
+	return "", false
+}
+
+// The arities of the connectives, with -1 for any number of arguments
+var connectives = map[string]int{
+	"!":   1,
+	"¬":   1,
+	"not": 1,
+	"&&":  2,
+	"and": -1,
+	"||":  2,
+	"or":  -1,
+	"imp": 2,
+	"==":  2,
+	"iff": 2,
+	"!=":  2,
+	"xor": 2,
+}
+
+// Adds the arguments referred to by a condition to the parents, and
+// checks the connectives of the condition
+func collectParents(t terms.Term, parents dung.ArgSet) error {
+	if arg, ok := argOf(t); ok {
+		parents[arg] = true
+		return nil
+	}
+	switch v := t.(type) {
+	case terms.Bool:
+		return nil
+	case terms.Compound:
+		n, ok := connectives[v.Functor]
+		if !ok || n >= 0 && len(v.Args) != n {
+			return fmt.Errorf("unsupported connective: %s/%d", v.Functor, len(v.Args))
+		}
+		for _, a := range v.Args {
+			if err := collectParents(a, parents); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported term: %s", t)
+}
+
+// Evaluates a condition, which has been checked by collectParents,
+// given the truth values of its parents
+func eval(t terms.Term, v map[dung.Arg]bool) bool {
+	if arg, ok := argOf(t); ok {
+		return v[arg]
+	}
+	switch t := t.(type) {
+	case terms.Bool:
+		return bool(t)
+	case terms.Compound:
+		switch t.Functor {
+		case "!", "¬", "not":
+			return !eval(t.Args[0], v)
+		case "&&", "and":
+			for _, a := range t.Args {
+				if !eval(a, v) {
+					return false
+				}
+			}
+			return true
+		case "||", "or":
+			for _, a := range t.Args {
+				if eval(a, v) {
+					return true
+				}
+			}
+			return false
+		case "imp":
+			return !eval(t.Args[0], v) || eval(t.Args[1], v)
+		case "==", "iff":
+			return eval(t.Args[0], v) == eval(t.Args[1], v)
+		case "!=", "xor":
+			return eval(t.Args[0], v) != eval(t.Args[1], v)
+		}
+	}
+	return false
+}
+
+// Returns the ADF of a Dung AF: the acceptance condition of each
+// argument is the conjunction of the negations of its attackers, or true
+// if the argument is not attacked. Attackers which are not arguments of
+// the AF are ignored. The grounded, complete and preferred
+// interpretations of the ADF are the grounded, complete and preferred
+// labellings of the AF, and its two-valued models are the labellings of
+// the stable extensions of the AF.
+func FromAF(af dung.AF) ADF {
+	args := append([]dung.Arg{}, af.Args()...)
+	declared := dung.NewArgSet(args...)
+	conds := make(map[dung.Arg]terms.Term)
+	for _, arg := range args {
+		negations := []terms.Term{}
+		for _, atk := range af.Atks()[arg] {
+			if declared.Contains(atk) {
+				negations = append(negations, terms.NewCompound("not", []terms.Term{terms.Atom(atk)}))
+			}
+		}
+		if len(negations) == 0 {
+			conds[arg] = terms.Bool(true)
+		} else {
+			conds[arg] = terms.NewCompound("and", negations)
+		}
+	}
+	adf, _ := NewADFFromTerms(args, conds)
+	return adf
+}
+
+func (adf *ADF) Args() []dung.Arg {
+	return adf.args
+}
+
+// Returns the acceptance condition of an argument
+func (adf *ADF) Condition(arg dung.Arg) terms.Term {
+	return adf.conds[arg]
+}
+
+// Returns the arguments which the acceptance condition of an argument
+// refers to
+func (adf *ADF) Parents(arg dung.Arg) []dung.Arg {
+	return adf.parents[arg]
+}
+
+func (adf ADF) String() string {
+	s := []string{}
+	for _, arg := range adf.args {
+		s = append(s, fmt.Sprintf("%s: %s", arg, adf.conds[arg]))
+	}
+	return fmt.Sprintf("{%s}", strings.Join(s, ", "))
+}
+
+// Returns the value of the characteristic operator of the ADF for an
+// argument, given a three-valued interpretation: in if the acceptance
+// condition is true for every two-valued completion of the interpretation
+// of the parents, out if it is false for every completion, and undecided
+// otherwise. The cost is exponential in the number of undecided parents.
+func (adf *ADF) gamma(arg dung.Arg, v dung.Labelling) dung.Label {
+	undecided := []dung.Arg{}
+	w := make(map[dung.Arg]bool)
+	for _, p := range adf.parents[arg] {
+		switch v.Get(p) {
+		case dung.In:
+			w[p] = true
+		case dung.Out:
+			w[p] = false
+		default:
+			undecided = append(undecided, p)
+		}
+	}
+	seenTrue, seenFalse := false, false
+	var complete func(i int) bool
+	complete = func(i int) bool {
+		if i == len(undecided) {
+			if eval(adf.conds[arg], w) {
+				seenTrue = true
+			} else {
+				seenFalse = true
+			}
+			return seenTrue && seenFalse
+		}
+		w[undecided[i]] = false
+		if complete(i + 1) {
+			return true
+		}
+		w[undecided[i]] = true
+		return complete(i + 1)
+	}
+	complete(0)
+	switch {
+	case seenTrue && !seenFalse:
+		return dung.In
+	case seenFalse && !seenTrue:
+		return dung.Out
+	default:
+		return dung.Undecided
+	}
+}
+
+// Returns the grounded interpretation of the ADF, the least fixpoint of
+// its characteristic operator w.r.t. the information ordering, computed
+// by iterating the operator starting with all arguments undecided.
+func (adf *ADF) GroundedInterpretation() dung.Labelling {
+	v := dung.NewLabelling()
+	for _, arg := range adf.args {
+		v[arg] = dung.Undecided
+	}
+	for {
+		changed := false
+		next := dung.NewLabelling()
+		for _, arg := range adf.args {
+			next[arg] = adf.gamma(arg, v)
+			if next[arg] != v[arg] {
+				changed = true
+			}
+		}
+		v = next
+		if !changed {
+			return v
+		}
+	}
+}
+
+// Searches the fixpoints of the characteristic operator, i.e. the
+// complete interpretations, extending the grounded interpretation,
+// which is below every complete interpretation. Undecided arguments of
+// the grounded interpretation are labelled using the given labels, and
+// a partial interpretation is pruned as soon as the operator disagrees
+// with the label of an argument whose parents are all labelled. f is
+// called with each fixpoint found.
+func (adf *ADF) fixpoints(labels []dung.Label, f func(dung.Labelling)) {
+	g := adf.GroundedInterpretation()
+	open := []dung.Arg{}
+	for _, arg := range adf.args {
+		if g[arg] == dung.Undecided {
+			open = append(open, arg)
+		}
+	}
+	v := dung.NewLabelling()
+	for arg, label := range g {
+		if label != dung.Undecided {
+			v[arg] = label
+		}
+	}
+	labelled := func(arg dung.Arg) bool {
+		_, ok := v[arg]
+		return ok
+	}
+	consistent := func() bool {
+		for _, arg := range adf.args {
+			if !labelled(arg) {
+				continue
+			}
+			ready := true
+			for _, p := range adf.parents[arg] {
+				if !labelled(p) {
+					ready = false
+					break
+				}
+			}
+			if ready && adf.gamma(arg, v) != v[arg] {
+				return false
+			}
+		}
+		return true
+	}
+	var search func(i int)
+	search = func(i int) {
+		if i == len(open) {
+			w := dung.NewLabelling()
+			for arg, label := range v {
+				w[arg] = label
+			}
+			f(w)
+			return
+		}
+		for _, label := range labels {
+			v[open[i]] = label
+			if consistent() {
+				search(i + 1)
+			}
+			delete(v, open[i])
+		}
+	}
+	if consistent() {
+		search(0)
+	}
+}
+
+// Returns the complete interpretations of the ADF, i.e. the fixpoints of
+// its characteristic operator. The search is exponential in the number of
+// arguments which are undecided in the grounded interpretation.
+func (adf *ADF) CompleteInterpretations() []dung.Labelling {
+	result := []dung.Labelling{}
+	adf.fixpoints([]dung.Label{dung.In, dung.Out, dung.Undecided}, func(v dung.Labelling) {
+		result = append(result, v)
+	})
+	return result
+}
+
+// Returns the preferred interpretations of the ADF, i.e. the complete
+// interpretations which are maximal w.r.t. the information ordering.
+func (adf *ADF) PreferredInterpretations() []dung.Labelling {
+	complete := adf.CompleteInterpretations()
+	result := []dung.Labelling{}
+	for i, v1 := range complete {
+		maximal := true
+		for j, v2 := range complete {
+			if i != j && moreInformative(v2, v1) {
+				maximal = false
+				break
+			}
+		}
+		if maximal {
+			result = append(result, v1)
+		}
+	}
+	return result
+}
+
+// Returns true if v2 is strictly more informative than v1, i.e. v2
+// agrees with every argument which is in or out in v1, and differs
+// from v1
+func moreInformative(v2, v1 dung.Labelling) bool {
+	for arg, label := range v1 {
+		if label != dung.Undecided && v2.Get(arg) != label {
+			return false
+		}
+	}
+	return !v1.Equals(v2)
+}
+
+// Returns the two-valued models of the ADF, i.e. the interpretations
+// labelling every argument in or out such that an argument is in iff its
+// acceptance condition is true.
+func (adf *ADF) Models() []dung.Labelling {
+	result := []dung.Labelling{}
+	adf.fixpoints([]dung.Label{dung.In, dung.Out}, func(v dung.Labelling) {
+		result = append(result, v)
+	})
+	return result
+}
@@ -0,0 +1,164 @@
+// Copyright © 2015 The Carneades Authors
+// This Source Code Form is subject to the terms of the
+// Mozilla Public License, v. 2.0. If a copy of the MPL
+// was not distributed with this file, You can obtain one
+// at http://mozilla.org/MPL/2.0/.
+
+// Goal selection using abduction: which statements would have to be
+// accepted or rejected to make a statement in or out.
+// See: Ballnat, S. and Gordon, T.F. Goal Selection in Argumentation
+// Processes — A Formal Model of Abduction in Argument Evaluation
+// Structures. COMMA 2010, 51–62.
+
+package caes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/carneades/carneades-4/src/engine/terms"
+)
+
+// A set of statements to be accepted, i.e. labelled In, and statements
+// to be rejected, i.e. labelled Out, for example by gathering evidence
+type Abduction struct {
+	Accepted []string // ids of statements, sorted
+	Rejected []string // ids of statements, sorted
+}
+
+// Returns the number of statements accepted or rejected
+func (a Abduction) Size() int {
+	return len(a.Accepted) + len(a.Rejected)
+}
+
+func (a Abduction) String() string {
+	s := []string{}
+	if len(a.Accepted) > 0 {
+		s = append(s, "accept "+strings.Join(a.Accepted, ", "))
+	}
+	if len(a.Rejected) > 0 {
+		s = append(s, "reject "+strings.Join(a.Rejected, ", "))
+	}
+	return strings.Join(s, "; ")
+}
+
+// Returns the minimal sets of statements which, if accepted or rejected,
+// make the statement with the given id, the goal, labelled as given, In
+// or Out, in the grounded labelling. The labelling is computed starting
+// with the assumptions, as by GroundedLabelling, and the accepted and
+// rejected statements, so that the proof standards of issues are applied
+// as usual. Accepting a position of an issue makes the other positions
+// Out, as assuming the position. Sets accepting several positions of an
+// issue, or rejecting a position which wins its issue nonetheless, are
+// excluded.
+//
+// Only statements on which the label of the goal depends are considered,
+// excluding the goal itself, and only statements which are not already
+// labelled In or Out respectively in the grounded labelling. A set is
+// minimal if no subset of it labels the goal as given too. The sets are
+// ranked by size, and only sets of at most max statements are computed,
+// since the search is exponential in max. If the goal already has the given label, the empty
+// set is the only minimal set.
+func (ag *ArgGraph) Abduce(id string, goal Label, max int) ([]Abduction, error) {
+	return ag.AbduceContext(context.Background(), id, goal, max)
+}
+
+// Returns the minimal sets of statements as Abduce, stopping the search
+// when ctx is done. Returns ctx.Err() if the search was stopped.
+func (ag *ArgGraph) AbduceContext(ctx context.Context, id string, goal Label, max int) ([]Abduction, error) {
+	if goal != In && goal != Out {
+		return nil, fmt.Errorf("the label of the goal is not in or out: %s", goal)
+	}
+	initial := NewLabelling()
+	initial.init(ag) // normalizes the ids of the statements
+	target, ok := ag.Statements[terms.Normalize(id)]
+	if !ok {
+		return nil, fmt.Errorf("unknown statement: %s", id)
+	}
+	// Returns the grounded labelling, given the accepted and rejected
+	// statements, and false if they are inconsistent
+	evaluate := func(accepted, rejected []*Statement) (Labelling, bool) {
+		l := NewLabelling()
+		for s, v := range initial {
+			l[s] = v
+		}
+		for _, s := range accepted {
+			l[s] = In
+		}
+		for _, s := range accepted {
+			if s.Issue == nil {
+				continue
+			}
+			for _, p := range s.Issue.Positions {
+				if p == s {
+					continue
+				}
+				if l[p] == In {
+					return nil, false // several positions accepted or assumed
+				}
+				l[p] = Out
+			}
+		}
+		for _, s := range rejected {
+			l[s] = Out
+		}
+		ag.fixpoint(l, nil)
+		for _, s := range rejected {
+			if l[s] != Out {
+				return nil, false // a rejected position won its issue
+			}
+		}
+		return l, true
+	}
+	base, _ := evaluate(nil, nil)
+	if base[target] == goal {
+		return []Abduction{{Accepted: []string{}, Rejected: []string{}}}, nil
+	}
+
+	// the candidates: statements to be accepted, labelled In, or
+	// rejected, labelled Out, which are not already labelled so
+	type candidate struct {
+		stmt  *Statement
+		label Label
+	}
+	candidates := []candidate{}
+	for _, s := range ancestors(target) {
+		if s == target {
+			continue
+		}
+		for _, label := range []Label{In, Out} {
+			if base[s] != label {
+				candidates = append(candidates, candidate{s, label})
+			}
+		}
+	}
+
+	results := []Abduction{}
+	minimalSubsets(ctx.Done(), len(candidates), max, func(chosen []int) bool {
+		a := Abduction{Accepted: []string{}, Rejected: []string{}}
+		accepted, rejected := []*Statement{}, []*Statement{}
+		for j, i := range chosen {
+			c := candidates[i]
+			if j > 0 && candidates[chosen[j-1]].stmt == c.stmt {
+				return false // at most one candidate per statement
+			}
+			if c.label == In {
+				a.Accepted = append(a.Accepted, c.stmt.Id)
+				accepted = append(accepted, c.stmt)
+			} else {
+				a.Rejected = append(a.Rejected, c.stmt.Id)
+				rejected = append(rejected, c.stmt)
+			}
+		}
+		if l, ok := evaluate(accepted, rejected); ok && l[target] == goal {
+			results = append(results, a)
+			return true
+		}
+		return false
+	})
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
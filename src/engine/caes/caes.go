@@ -100,15 +100,6 @@ type Scheme struct {
 	Conclusions []string // list of atomic formulas or schema variables
 }
 
-// Proof Standards
-type Standard int
-
-const (
-	PE  Standard = iota // preponderance of the evidence
-	CCE                 // clear and convincing evidence
-	BRD                 // beyond reasonable doubt
-)
-
 type Statement struct {
 	Id       string // a ground atomic formula, using Prolog syntax
 	Metadata Metadata
@@ -367,41 +358,29 @@ func (issue *Issue) ReadyToBeResolved(l Labelling) bool {
 	return true
 }
 
-// Apply a proof standard to check whether w1 is strictly greater than
-// w2, where w1 and w2 are argument weights
-// Note: PE are indistinguishable in this new model
-func (std Standard) greater(w1, w2 float64) bool {
-	alpha := 0.5
-	beta := 0.3
-	switch std {
-	case PE:
-		return w1 > w2
-	case CCE:
-		return w1 > w2 && (w1-w2 > alpha)
-	case BRD:
-		return w1 > w2 && (w1-w2 > alpha) && w2 < beta
-	default:
-		return false
-	}
-}
-
 // Apply the proof standard of an issue to each of its positions and update
 // the labelling accordingly. After resolving the issue, at most
 // one of its positions will be In and all the others will be Out.
 // (No position will remain Undecided.) The issue is assumed to be ready to be
 // resolved before this method is called.
 func (issue *Issue) Resolve(l Labelling) {
-	var maxArgWeight = make(map[*Statement]float64)
+	issue.resolve(l, func(arg *Argument) float64 { return arg.GetWeight(l) })
+}
+
+// Resolves the issue, as in Resolve, using the given function to weigh
+// the arguments, and returns the maximum weights of the arguments pro
+// each position and the winning position, if any.
+func (issue *Issue) resolve(l Labelling, weight func(*Argument) float64) (maxArgWeight map[*Statement]float64, winner *Statement) {
+	maxArgWeight = make(map[*Statement]float64)
 	for _, p := range issue.Positions {
 		maxArgWeight[p] = 0.0
 		for _, arg := range p.Args {
-			w := arg.GetWeight(l)
+			w := weight(arg)
 			if w > maxArgWeight[p] {
 				maxArgWeight[p] = w
 			}
 		}
 	}
-	var winner *Statement
 PositionLoop:
 	for _, p1 := range issue.Positions {
 		if maxArgWeight[p1] == 0.0 {
@@ -428,6 +407,7 @@ PositionLoop:
 			l[p] = Out
 		}
 	}
+	return maxArgWeight, winner
 }
 
 // A argument has 0.0 weight if it is undercut or inapplicable.
@@ -472,31 +452,60 @@ func (stmt *Statement) Unsupported(l Labelling) bool {
 func (ag *ArgGraph) GroundedLabelling() Labelling {
 	l := NewLabelling()
 	l.init(ag)
+	ag.fixpoint(l, nil)
+	return l
+}
+
+// Extends an initial labelling of an argument graph by labelling its
+// Undecided statements until a fixpoint is reached. If ex is not nil, an
+// explanation of each label assigned is recorded in ex.
+func (ag *ArgGraph) fixpoint(l Labelling, ex Explanations) {
 	var changed bool
-	for {
+	for step := 1; ; step++ {
 		changed = false // assumption
 		// Try to label Undecided statements
 		for _, stmt := range ag.Statements {
 			if l[stmt] == Undecided {
 				if stmt.Unsupported(l) {
 					// make unsupported statements Out
+					if ex != nil {
+						ex[stmt] = newExplanation(l, stmt, Out, UnsupportedRule, step, stmt)
+					}
 					l[stmt] = Out
 					changed = true
 				} else if stmt.Issue == nil && stmt.Supported(l) {
 					// make supported nonissues In
+					if ex != nil {
+						ex[stmt] = newExplanation(l, stmt, In, SupportedRule, step, stmt)
+					}
 					l[stmt] = In
 					changed = true
 				} else if stmt.Issue != nil && stmt.Issue.ReadyToBeResolved(l) {
 					// Apply proof standards to label the positions of issues
 					// ready to be resolved
-					stmt.Issue.Resolve(l)
+					issue := stmt.Issue
+					var e *Explanation
+					if ex != nil {
+						e = newExplanation(l, nil, Undecided, IssueResolvedRule, step, issue.Positions...)
+						e.Issue = issue
+					}
+					weights, winner := issue.resolve(l, func(arg *Argument) float64 { return arg.GetWeight(l) })
+					if ex != nil {
+						e.Weights, e.Winner = weights, winner
+						for _, p := range issue.Positions {
+							e2 := *e
+							e2.Statement = p
+							e2.Label = l[p]
+							ex[p] = &e2
+						}
+					}
 					changed = true
 				}
 			}
 		}
 		// return if a fixpoint has been found
 		if !changed {
-			return l
+			return
 		}
 	}
 }
@@ -0,0 +1,166 @@
+// Copyright © 2015 The Carneades Authors
+// This Source Code Form is subject to the terms of the
+// Mozilla Public License, v. 2.0. If a copy of the MPL
+// was not distributed with this file, You can obtain one
+// at http://mozilla.org/MPL/2.0/.
+
+// Translations between CAES argument graphs and Dung AFs
+
+package caes
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/carneades/carneades-4/src/engine/dung"
+)
+
+// Translates an argument graph into a Dung AF. The arguments of the AF
+// are the ids of the arguments of the graph, and an argument b attacks an
+// argument a of the graph if
+//
+//   - b undercuts a, i.e. the conclusion of b is the undercutter of a,
+//   - b rebuts a, i.e. the conclusions of a and b are different positions
+//     of the same issue, or
+//   - b undermines a, i.e. the conclusion of b and some premise of a are
+//     different positions of the same issue.
+//
+// The translation abstracts from the weights of the arguments, proof
+// standards and assumptions: rebuttals are symmetric attacks, whatever
+// the weights of the arguments, and the premises of the arguments are
+// assumed to hold unless undermined. So the grounded extension of the AF
+// corresponds to the grounded labelling of the graph, i.e. contains the
+// arguments whose conclusions are in, only for graphs in which every
+// premise is assumed or supported and no argument outweighs its
+// rebuttals, such as the graphs of AFs constructed by FromAF.
+func (ag *ArgGraph) ToAF() dung.AF {
+	ids := []string{}
+	for id := range ag.Arguments {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	// Are s1 and s2 different positions of the same issue?
+	alternatives := func(s1, s2 *Statement) bool {
+		return s1 != nil && s2 != nil && s1 != s2 && s1.Issue != nil && s1.Issue == s2.Issue
+	}
+	args := []dung.Arg{}
+	atks := make(map[dung.Arg][]dung.Arg)
+	for _, id := range ids {
+		a := ag.Arguments[id]
+		args = append(args, dung.Arg(id))
+		for _, id2 := range ids {
+			b := ag.Arguments[id2]
+			attacks := b.Conclusion != nil && b.Conclusion == a.Undercutter ||
+				alternatives(b.Conclusion, a.Conclusion)
+			for _, p := range a.Premises {
+				attacks = attacks || alternatives(b.Conclusion, p.Stmt)
+			}
+			if attacks {
+				atks[dung.Arg(id)] = append(atks[dung.Arg(id)], dung.Arg(id2))
+			}
+		}
+	}
+	return dung.NewAF(args, atks)
+}
+
+// Identifiers which can be used in terms without quotes
+var plainIdentifier = regexp.MustCompile(`^([a-z][A-Za-z0-9_]*|0|[1-9][0-9]*)$`)
+
+// Returns a term with the given predicate and arguments, quoting the
+// arguments if necessary
+func atom(predicate string, args ...dung.Arg) string {
+	s := predicate + "("
+	for i, arg := range args {
+		if i > 0 {
+			s += ","
+		}
+		if plainIdentifier.MatchString(string(arg)) {
+			s += string(arg)
+		} else {
+			s += fmt.Sprintf("%q", string(arg))
+		}
+	}
+	return s + ")"
+}
+
+// Returns the id of the statement of the argument graph constructed by
+// FromAF which is in, out or undecided in the grounded labelling of the
+// graph iff the argument is in, out or undecided in the grounded
+// labelling of the AF
+func AcceptabilityStatement(arg dung.Arg) string {
+	return atom("acceptable", arg)
+}
+
+// Translates a Dung AF into an argument graph, as in the examples
+// dung-reinstatement.yml and dung-attack-cycle.yml. Each argument a of
+// the AF becomes an argument with the id a concluding that a is
+// acceptable, the statement AcceptabilityStatement(a). If a is attacked,
+// the argument has an undercutter, undercut(a), and the acceptability of
+// a is at issue, with the positions acceptable(a) and unacceptable(a).
+// Each attack of b on a becomes an argument attack(b,a), concluding
+// undercut(a), which is undercut by the undercutter of b, if b is
+// attacked. Attacks by arguments which are not arguments of the AF are
+// ignored.
+//
+// The graph has no assumptions. The grounded labelling of the graph
+// labels acceptable(a) as the grounded labelling of the AF labels a.
+// The graph can be translated back into an AF using ToAF, which results
+// in the AF extended with an argument for each attack, attack(b,a),
+// which is attacked by the attackers of b and attacks a.
+func FromAF(af dung.AF) *ArgGraph {
+	ag := NewArgGraph()
+	declared := dung.NewArgSet(af.Args()...)
+	attacked := func(arg dung.Arg) bool {
+		for _, atk := range af.Atks()[arg] {
+			if declared.Contains(atk) {
+				return true
+			}
+		}
+		return false
+	}
+	statement := func(id, text string) *Statement {
+		s := NewStatement()
+		s.Id = id
+		s.Text = text
+		ag.Statements[id] = s
+		return s
+	}
+	undercutters := make(map[dung.Arg]*Statement)
+	for _, arg := range af.Args() {
+		acceptable := statement(AcceptabilityStatement(arg), fmt.Sprintf("%s is acceptable.", arg))
+		a := NewArgument()
+		a.Id = string(arg)
+		a.Conclusion = acceptable
+		acceptable.Args = append(acceptable.Args, a)
+		ag.Arguments[a.Id] = a
+		if !attacked(arg) {
+			continue
+		}
+		uc := statement(atom("undercut", arg), fmt.Sprintf("%s is undercut.", arg))
+		uc.IsUndercutter = true
+		a.Undercutter = uc
+		undercutters[arg] = uc
+		unacceptable := statement(atom("unacceptable", arg), fmt.Sprintf("%s is not acceptable.", arg))
+		issue := NewIssue()
+		issue.Id = atom("issue", arg)
+		issue.Positions = []*Statement{acceptable, unacceptable}
+		acceptable.Issue = issue
+		unacceptable.Issue = issue
+		ag.Issues[issue.Id] = issue
+	}
+	for _, arg := range af.Args() {
+		for _, atk := range af.Atks()[arg] {
+			if !declared.Contains(atk) {
+				continue
+			}
+			a := NewArgument()
+			a.Id = atom("attack", atk, arg)
+			a.Conclusion = undercutters[arg]
+			a.Undercutter = undercutters[atk] // nil if atk is not attacked
+			a.Conclusion.Args = append(a.Conclusion.Args, a)
+			ag.Arguments[a.Id] = a
+		}
+	}
+	return ag
+}
@@ -8,6 +8,7 @@
 package dot
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
@@ -65,6 +66,7 @@ type gmlNode struct {
 	nodeLabel       string
 	underlinedLabel bool
 	shapeType       string
+	description     string
 }
 
 var graphNr, nodeNr, edgeNr int
@@ -159,6 +161,14 @@ func trimmString(inStr string, underlined bool) string {
 	return outStr
 }
 
+// Returns a quoted dot string, with escaped quotes and line breaks
+func escape(s string) string {
+	s = strings.Replace(s, "\\", "\\\\", -1)
+	s = strings.Replace(s, "\"", "\\\"", -1)
+	s = strings.Replace(s, "\n", "\\n", -1)
+	return "\"" + s + "\""
+}
+
 func pNodes(w io.Writer, nodes []gmlNode) {
 
 	p(w, "node [shape=box, style=filled, penwidth=1, fontname="+font+", fontsize="+fontsize+"]")
@@ -181,8 +191,14 @@ func pNodes(w io.Writer, nodes []gmlNode) {
 		// height := "30.0"
 		// width := calculateWidth(len(node.nodeLabel))
 
+		tooltip := ""
+		if node.description != "" {
+			tooltip = ", tooltip=" + escape(node.description)
+		}
+
 		p(w, "node_"+node.id+
 			" [label="+label+
+			tooltip+
 			", penwidth="+node.borderWidth+
 			", fillcolor=\""+fillcolor+"\""+
 			", shape=\""+node.shapeType+"\""+
@@ -218,7 +234,7 @@ func pEdges(w io.Writer, edges []gmlEdge) {
 	}
 }
 
-func mkNodesAndEdges(ag *caes.ArgGraph) (nodes []gmlNode, edges []gmlEdge, err error) {
+func mkNodesAndEdges(ag *caes.ArgGraph, ex caes.Explanations) (nodes []gmlNode, edges []gmlEdge, err error) {
 	assums := caes.SliceToMap(ag.Assumptions)
 	stat2Node := make(map[string]string)
 	firstNode := true
@@ -230,6 +246,11 @@ func mkNodesAndEdges(ag *caes.ArgGraph) (nodes []gmlNode, edges []gmlEdge, err e
 		nNode := newGmlNode() // shapeType [] (rectangle)
 		stat2Node[stat.Id] = nNode.id
 		nNode.nodeLabel = stat.Text
+		if e, ok := ex[stat]; ok {
+			var b bytes.Buffer
+			e.WriteText(&b)
+			nNode.description = b.String()
+		}
 		if assums[stat.Id] {
 			nNode.underlinedLabel = true
 		}
@@ -331,16 +352,7 @@ func mkNodesAndEdges(ag *caes.ArgGraph) (nodes []gmlNode, edges []gmlEdge, err e
 	for _, issue := range ag.Issues {
 		nNode := newGmlNode()
 		nNode.shapeType = hexagon
-		s := ""
-		switch issue.Standard {
-		case 0:
-			s = "PE"
-		case 1:
-			s = "CCE"
-		case 2:
-			s = "BRD"
-		}
-		nNode.nodeLabel = fmt.Sprintf("%s: %s", issue.Id, s)
+		nNode.nodeLabel = fmt.Sprintf("%s: %s", issue.Id, issue.Standard)
 		if firstNode {
 			nodes = []gmlNode{nNode}
 			firstNode = false
@@ -370,7 +382,13 @@ func mkNodesAndEdges(ag *caes.ArgGraph) (nodes []gmlNode, edges []gmlEdge, err e
 }
 
 func Export(w io.Writer, ag *caes.ArgGraph) error {
-	nodes, edges, err := mkNodesAndEdges(ag)
+	return ExportExplanations(w, ag, nil)
+}
+
+// Exports the argument graph, as Export, annotating the statements with
+// the explanations of their labels, as tooltips
+func ExportExplanations(w io.Writer, ag *caes.ArgGraph, ex caes.Explanations) error {
+	nodes, edges, err := mkNodesAndEdges(ag, ex)
 	if err != nil {
 		return err
 	}
@@ -8,6 +8,8 @@
 package graphml
 
 import (
+	"bytes"
+	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
@@ -66,6 +68,7 @@ type gmlNode struct {
 	nodeLabel       string
 	underlinedLabel bool
 	shapeType       string
+	description     string
 }
 
 var graphNr, nodeNr, edgeNr int
@@ -182,9 +185,15 @@ func pGeometry(w io.Writer, lbl string) {
 func pNodes(w io.Writer, nodes []gmlNode) {
 
 	for _, node := range nodes {
-		p(w, "   <node id=\""+node.id+"\">",
-			"      <data key=\"d5\"/>",
-			"      <data key=\"d6\">",
+		p(w, "   <node id=\""+node.id+"\">")
+		if node.description == "" {
+			p(w, "      <data key=\"d5\"/>")
+		} else {
+			p1(w, "      <data key=\"d5\">")
+			xml.EscapeText(w, []byte(node.description))
+			p(w, "</data>")
+		}
+		p(w, "      <data key=\"d6\">",
 			"      <y:ShapeNode>")
 
 		/*	height := "30.0"
@@ -250,7 +259,7 @@ func pEdges(w io.Writer, edges []gmlEdge) {
 	}
 }
 
-func mkNodesAndEdges(ag caes.ArgGraph) (nodes []gmlNode, edges []gmlEdge, err error) {
+func mkNodesAndEdges(ag caes.ArgGraph, ex caes.Explanations) (nodes []gmlNode, edges []gmlEdge, err error) {
 	stat2Node := make(map[string]string)
 	firstNode := true
 	firstEdge := true
@@ -263,6 +272,11 @@ func mkNodesAndEdges(ag caes.ArgGraph) (nodes []gmlNode, edges []gmlEdge, err er
 		nNode := newGmlNode() // shapeType [] (rectangle)
 		stat2Node[stat.Id] = nNode.id
 		nNode.nodeLabel = stat.Text
+		if e, ok := ex[stat]; ok {
+			var b bytes.Buffer
+			e.WriteText(&b)
+			nNode.description = b.String()
+		}
 		if assums[stat.Id] {
 			nNode.underlinedLabel = true
 		}
@@ -363,16 +377,7 @@ func mkNodesAndEdges(ag caes.ArgGraph) (nodes []gmlNode, edges []gmlEdge, err er
 	for _, issue := range ag.Issues {
 		nNode := newGmlNode()
 		nNode.shapeType = hexagon
-		s := ""
-		switch issue.Standard {
-		case 0:
-			s = "PE"
-		case 1:
-			s = "CCE"
-		case 2:
-			s = "BRD"
-		}
-		nNode.nodeLabel = fmt.Sprintf("%s: %s", issue.Id, s)
+		nNode.nodeLabel = fmt.Sprintf("%s: %s", issue.Id, issue.Standard)
 		if firstNode {
 			nodes = []gmlNode{nNode}
 			firstNode = false
@@ -402,11 +407,17 @@ func mkNodesAndEdges(ag caes.ArgGraph) (nodes []gmlNode, edges []gmlEdge, err er
 }
 
 func Export(w io.Writer, ag *caes.ArgGraph) error {
+	return ExportExplanations(w, ag, nil)
+}
+
+// Exports the argument graph, as Export, annotating the statements with
+// the explanations of their labels, as descriptions of the nodes
+func ExportExplanations(w io.Writer, ag *caes.ArgGraph, ex caes.Explanations) error {
 	pHead(w)
 	p(w, "<graph edgedefault=\"directed\" id=\"G"+
 		fmt.Sprintf("%d", graphNr)+"\">")
 	graphNr++
-	nodes, edges, err := mkNodesAndEdges(*ag)
+	nodes, edges, err := mkNodesAndEdges(*ag, ex)
 	if err != nil {
 		return err
 	}
@@ -20,6 +20,7 @@ import (
 	"github.com/carneades/carneades-4/src/engine/terms"
 	"gopkg.in/yaml.v2"
 	// "log"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -76,7 +77,7 @@ type (
 		id           string
 		Meta         caes.Metadata
 		Positions    []string
-		Standard     string
+		Standard     interface{} // string || {name: {parameter: float64}}
 		caesStandard caes.Standard
 	}
 	umLabel struct {
@@ -155,17 +156,11 @@ func scanArgMapGraph(m *argMapGraph) (*argMapGraph, error) {
 	for id, iss := range m.Issues {
 		iss.id = id
 		// fmt.Printf(" issue: %s standard: \"%s\"\n", id, iss.Standard)
-		switch iss.Standard {
-		case "", "PE", "pe":
-			iss.caesStandard = caes.PE
-		case "CCE", "cce":
-			iss.caesStandard = caes.CCE
-		case "BRD", "brd":
-			iss.caesStandard = caes.BRD
-		default:
-			return nil,
-				errors.New("*** Error: issues: ... standard: expected PE, CCE, BRD, wrong: " + iss.Standard + " \n")
+		std, err := iface2standard(iss.Standard)
+		if err != nil {
+			return nil, err
 		}
+		iss.caesStandard = std
 	}
 	// scan Stantements set caesStatements
 	// -----------------------------------
@@ -1182,25 +1177,11 @@ func iface2issues(value interface{}, yamlIssues map[string]umIssue) (map[string]
 								}
 
 							case "standard":
-								switch ivT := issueValue.(type) {
-								case string:
-									issueValueStr := issueValue.(string)
-									// fmt.Printf("%s\n", issueValueStr)
-									switch issueValueStr {
-									case "PE", "pe":
-										issue.caesStandard = caes.PE
-									case "CCE", "cce":
-										issue.caesStandard = caes.CCE
-									case "BRD", "brd":
-										issue.caesStandard = caes.BRD
-									default:
-										return yamlIssues,
-											errors.New("*** Error: issues: ... standard: expected PE, CCE, BRD, wrong: " + fmt.Sprintf("%v", issueValue) + " \n")
-									}
-								default:
-									return yamlIssues,
-										errors.New("*** Error: unexpected issue-value-type:" + fmt.Sprintf("%v", ivT) + "\n")
+								std, err := iface2standard(issueValue)
+								if err != nil {
+									return yamlIssues, err
 								}
+								issue.caesStandard = std
 
 							case "meta", "metadata":
 								var err error
@@ -1226,6 +1207,55 @@ func iface2issues(value interface{}, yamlIssues map[string]umIssue) (map[string]
 	return yamlIssues, nil
 }
 
+// iface2standard: reads a proof standard, given by its name, e.g. CCE,
+// or by its name and the values of some of its parameters, e.g.
+// {cce: {alpha: 0.4}}. The name is not checked, so that unknown
+// standards can be reported by the validation.
+func iface2standard(value interface{}) (caes.Standard, error) {
+	switch v := value.(type) {
+	case nil:
+		return caes.PE, nil
+	case string:
+		if v == "" {
+			return caes.PE, nil
+		}
+		return caes.NewStandard(v, nil), nil
+	case map[interface{}]interface{}:
+		if len(v) != 1 {
+			return caes.PE, errors.New("*** Error: issues: ... standard: expected a single proof standard, wrong: " + fmt.Sprintf("%v", value) + " \n")
+		}
+		for name, params := range v {
+			nameStr, ok := name.(string)
+			if !ok {
+				return caes.PE, errors.New("*** Error: issues: ... standard: expected the name of a proof standard, wrong: " + fmt.Sprintf("%v", name) + " \n")
+			}
+			parameters := map[string]float64{}
+			switch p := params.(type) {
+			case nil:
+			case map[interface{}]interface{}:
+				for k, val := range p {
+					kStr, ok := k.(string)
+					if !ok {
+						return caes.PE, errors.New("*** Error: issues: ... standard: expected a parameter name, wrong: " + fmt.Sprintf("%v", k) + " \n")
+					}
+					switch x := val.(type) {
+					case int:
+						parameters[strings.ToLower(kStr)] = float64(x)
+					case float64:
+						parameters[strings.ToLower(kStr)] = x
+					default:
+						return caes.PE, errors.New("*** Error: issues: ... standard: expected a number as the value of " + kStr + ", wrong: " + fmt.Sprintf("%v", val) + " \n")
+					}
+				}
+			default:
+				return caes.PE, errors.New("*** Error: issues: ... standard: expected the parameters of " + nameStr + ", wrong: " + fmt.Sprintf("%v", params) + " \n")
+			}
+			return caes.NewStandard(nameStr, parameters), nil
+		}
+	}
+	return caes.PE, errors.New("*** Error: issues: ... standard: expected a proof standard, wrong: " + fmt.Sprintf("%v", value) + " \n")
+}
+
 func iface2assumps(value interface{}, yamlAssumps map[string]bool) (map[string]bool, error) {
 	// fmt.Printf("Assumptions: ")
 	switch value.(type) {
@@ -1675,16 +1705,23 @@ func writeArgGraph1(noRefs bool, f io.Writer, caesAg *caes.ArgGraph) {
 					fmt.Fprintf(f, "]\n")
 				}*/
 			fmt.Fprintf(f, "        standard: ")
-			s := "???"
-			switch is_val.Standard {
-			case 0:
-				s = "PE"
-			case 1:
-				s = "CCE"
-			case 2:
-				s = "BRD"
-			}
-			fmt.Fprintf(f, "%s\n", s)
+			if len(is_val.Standard.Parameters) == 0 {
+				fmt.Fprintf(f, "%s\n", is_val.Standard)
+			} else {
+				fmt.Fprintf(f, "{%s: {", is_val.Standard.Name)
+				params := []string{}
+				for k := range is_val.Standard.Parameters {
+					params = append(params, k)
+				}
+				sort.Strings(params)
+				for i, k := range params {
+					if i > 0 {
+						fmt.Fprintf(f, ", ")
+					}
+					fmt.Fprintf(f, "%s: %v", k, is_val.Standard.Parameters[k])
+				}
+				fmt.Fprintf(f, "}}\n")
+			}
 		}
 	}
 
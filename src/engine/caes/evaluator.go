@@ -0,0 +1,362 @@
+// Copyright © 2015 The Carneades Authors
+// This Source Code Form is subject to the terms of the
+// Mozilla Public License, v. 2.0. If a copy of the MPL
+// was not distributed with this file, You can obtain one
+// at http://mozilla.org/MPL/2.0/.
+
+// Dependency-ordered, incremental evaluation of argument graphs which
+// are extended one argument or assumption at a time.
+
+package caes
+
+import (
+	"sort"
+
+	"github.com/carneades/carneades-4/src/engine/terms"
+)
+
+// An Evaluator maintains the grounded labelling of an argument graph,
+// the same labelling as computed by GroundedLabelling, while arguments
+// and assumptions are added using the methods of the evaluator.
+//
+// The label of a statement depends on the labels of the premises and
+// undercutters of its arguments, and of the other positions of the
+// issues of these premises, which are used by criteria weighing
+// functions. The label of a position depends, in addition, on the
+// labels the other positions of its issue depend on, since the
+// positions of an issue are labelled together, by resolving the issue.
+// The evaluator labels the strongly connected components of this
+// dependency graph in topological order, so that the statements of a
+// component are labelled after the statements they depend on. Only the
+// statements of cyclic components need to be visited more than once.
+// The weights of arguments are cached once the labels they depend on
+// have been fixed.
+//
+// After adding an argument or assumption, only the statements affected
+// by it, i.e. the statements depending directly or indirectly on its
+// conclusion or the assumed statement, are relabelled.
+type Evaluator struct {
+	ag         *ArgGraph
+	l          Labelling
+	dependents map[*Statement]map[*Statement]bool // statements to the statements depending on them
+	weights    map[*Argument]float64              // cached weights
+}
+
+// Returns an evaluator of the argument graph. The assumptions of the
+// graph are normalized, as by GroundedLabelling.
+func NewEvaluator(ag *ArgGraph) *Evaluator {
+	e := &Evaluator{
+		ag:         ag,
+		l:          NewLabelling(),
+		dependents: make(map[*Statement]map[*Statement]bool),
+		weights:    make(map[*Argument]float64),
+	}
+	e.l.init(ag)
+	R := make(map[*Statement]bool)
+	for _, s := range ag.Statements {
+		R[s] = true
+		e.addDependencies(s)
+	}
+	e.update(R)
+	return e
+}
+
+func (e *Evaluator) ArgGraph() *ArgGraph {
+	return e.ag
+}
+
+// Returns the grounded labelling of the argument graph, which is updated
+// when the graph is extended using the evaluator
+func (e *Evaluator) GroundedLabelling() Labelling {
+	return e.l
+}
+
+// Returns the statements the label of the statement depends on
+func dependencies(s *Statement) []*Statement {
+	args := s.Args
+	deps := []*Statement{}
+	if s.Issue != nil {
+		args = nil
+		for _, p := range s.Issue.Positions {
+			args = append(args, p.Args...)
+			if p != s {
+				deps = append(deps, p)
+			}
+		}
+	}
+	for _, arg := range args {
+		if arg.Undercutter != nil {
+			deps = append(deps, arg.Undercutter)
+		}
+		for _, p := range arg.Premises {
+			deps = append(deps, p.Stmt)
+			if p.Stmt.Issue != nil {
+				deps = append(deps, p.Stmt.Issue.Positions...)
+			}
+		}
+	}
+	return deps
+}
+
+func (e *Evaluator) addDependencies(s *Statement) {
+	for _, d := range dependencies(s) {
+		if e.dependents[d] == nil {
+			e.dependents[d] = make(map[*Statement]bool)
+		}
+		e.dependents[d][s] = true
+	}
+}
+
+// Returns the statements depending directly or indirectly on the given
+// statements, including these statements
+func (e *Evaluator) affected(stmts ...*Statement) map[*Statement]bool {
+	R := make(map[*Statement]bool)
+	queue := []*Statement{}
+	for _, s := range stmts {
+		if !R[s] {
+			R[s] = true
+			queue = append(queue, s)
+		}
+	}
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+		for t := range e.dependents[s] {
+			if !R[t] {
+				R[t] = true
+				queue = append(queue, t)
+			}
+		}
+	}
+	return R
+}
+
+// Returns the statement of the argument graph with the id of the given
+// statement, adding the statement to the graph if there is none, and
+// recording it in added
+func (e *Evaluator) statement(s *Statement, added *[]*Statement) *Statement {
+	id := terms.Normalize(s.Id)
+	if s2, ok := e.ag.Statements[id]; ok {
+		return s2
+	}
+	e.ag.Statements[id] = s
+	e.addDependencies(s)
+	*added = append(*added, s)
+	return s
+}
+
+// Adds the argument to the argument graph and updates the labelling.
+// Statements of the argument which are not statements of the graph, by
+// id, are added too. Arguments with the id of an argument of the graph
+// are ignored.
+func (e *Evaluator) AddArgument(arg *Argument) {
+	if _, ok := e.ag.Arguments[arg.Id]; ok {
+		return
+	}
+	added := []*Statement{}
+	for i, p := range arg.Premises {
+		arg.Premises[i].Stmt = e.statement(p.Stmt, &added)
+	}
+	if arg.Undercutter != nil {
+		arg.Undercutter = e.statement(arg.Undercutter, &added)
+	}
+	arg.Conclusion = e.statement(arg.Conclusion, &added)
+	arg.Conclusion.Args = append(arg.Conclusion.Args, arg)
+	e.ag.Arguments[arg.Id] = arg
+	changed := []*Statement{arg.Conclusion}
+	if arg.Conclusion.Issue != nil {
+		changed = arg.Conclusion.Issue.Positions
+	}
+	for _, s := range changed {
+		e.addDependencies(s)
+	}
+	e.update(e.affected(append(changed, added...)...))
+}
+
+// Adds the statement with the given id to the assumptions of the
+// argument graph and updates the labelling
+func (e *Evaluator) AddAssumption(id string) {
+	id = terms.Normalize(id)
+	if e.ag.assums[id] {
+		return
+	}
+	e.ag.AddAssumption(id)
+	s, ok := e.ag.Statements[id]
+	if !ok {
+		return
+	}
+	changed := []*Statement{s}
+	if s.Issue != nil {
+		changed = s.Issue.Positions
+	}
+	e.update(e.affected(changed...))
+}
+
+func (e *Evaluator) assumed(s *Statement) bool {
+	return e.ag.assums[terms.Normalize(s.Id)]
+}
+
+// Relabels the statements of R, given the labels of the other
+// statements, which do not depend on the statements of R
+func (e *Evaluator) update(R map[*Statement]bool) {
+	// initialize the labels of R, as Labelling.init
+	for s := range R {
+		delete(e.l, s)
+		for _, arg := range s.Args {
+			delete(e.weights, arg)
+		}
+		if e.assumed(s) {
+			e.l[s] = In
+		}
+	}
+	for s := range R {
+		if e.l[s] == Undecided && s.Issue != nil {
+			for _, p := range s.Issue.Positions {
+				if e.assumed(p) {
+					e.l[s] = Out
+					break
+				}
+			}
+		}
+	}
+	for _, component := range e.components(R) {
+		e.label(component)
+	}
+}
+
+// Returns the strongly connected components of the dependency graph
+// restricted to the statements of R, using Tarjan's algorithm. The
+// components are ordered topologically: every statement a statement
+// depends on is in the same or some earlier component. The statements
+// of each component are sorted by id.
+func (e *Evaluator) components(R map[*Statement]bool) [][]*Statement {
+	stmts := []*Statement{}
+	for s := range R {
+		stmts = append(stmts, s)
+	}
+	sort.Slice(stmts, func(i, j int) bool { return stmts[i].Id < stmts[j].Id })
+	index := make(map[*Statement]int) // 0 means not yet visited
+	lowlink := make(map[*Statement]int)
+	onStack := make(map[*Statement]bool)
+	stack := []*Statement{}
+	counter := 0
+	components := [][]*Statement{}
+
+	var connect func(v *Statement)
+	connect = func(v *Statement) {
+		counter++
+		index[v] = counter
+		lowlink[v] = counter
+		stack = append(stack, v)
+		onStack[v] = true
+		for w := range e.dependents[v] {
+			if !R[w] {
+				continue
+			}
+			if index[w] == 0 {
+				connect(w)
+				if lowlink[w] < lowlink[v] {
+					lowlink[v] = lowlink[w]
+				}
+			} else if onStack[w] && index[w] < lowlink[v] {
+				lowlink[v] = index[w]
+			}
+		}
+		if lowlink[v] == index[v] {
+			component := []*Statement{}
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				component = append(component, w)
+				if w == v {
+					break
+				}
+			}
+			sort.Slice(component, func(i, j int) bool { return component[i].Id < component[j].Id })
+			components = append(components, component)
+		}
+	}
+
+	for _, s := range stmts {
+		if index[s] == 0 {
+			connect(s)
+		}
+	}
+
+	// Tarjan's algorithm finds the components in reverse topological order
+	for i, j := 0, len(components)-1; i < j; i, j = i+1, j-1 {
+		components[i], components[j] = components[j], components[i]
+	}
+	return components
+}
+
+// Returns the weight of the argument in the current labelling, caching
+// it if the labels it depends on have been fixed
+func (e *Evaluator) weight(arg *Argument) float64 {
+	if w, ok := e.weights[arg]; ok {
+		return w
+	}
+	w := arg.GetWeight(e.l)
+	fixed := arg.Undercut(e.l) == In
+	if !fixed && arg.Undercut(e.l) == Out {
+		fixed = true
+		for _, p := range arg.Premises {
+			if e.l[p.Stmt] == Undecided {
+				fixed = false
+				break
+			}
+			if p.Stmt.Issue != nil {
+				for _, pos := range p.Stmt.Issue.Positions {
+					fixed = fixed && e.l[pos] != Undecided
+				}
+			}
+		}
+	}
+	if fixed {
+		e.weights[arg] = w
+	}
+	return w
+}
+
+// Labels the statements of a component, whose dependencies outside of
+// the component have been labelled, applying the rules of
+// GroundedLabelling until a fixpoint is reached. The statements of an
+// acyclic component are labelled in a single pass.
+func (e *Evaluator) label(component []*Statement) {
+	l := e.l
+	unsupported := func(s *Statement) bool {
+		for _, arg := range s.Args {
+			if !arg.Applicable(l) || e.weight(arg) > 0 {
+				return false
+			}
+		}
+		return true
+	}
+	supported := func(s *Statement) bool {
+		for _, arg := range s.Args {
+			if arg.Applicable(l) && e.weight(arg) > 0 {
+				return true
+			}
+		}
+		return false
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, s := range component {
+			if l[s] != Undecided {
+				continue
+			}
+			if unsupported(s) {
+				l[s] = Out
+				changed = true
+			} else if s.Issue == nil && supported(s) {
+				l[s] = In
+				changed = true
+			} else if s.Issue != nil && s.Issue.ReadyToBeResolved(l) {
+				s.Issue.resolve(l, e.weight)
+				changed = true
+			}
+		}
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright © 2015 The Carneades Authors
+// This Source Code Form is subject to the terms of the
+// Mozilla Public License, v. 2.0. If a copy of the MPL
+// was not distributed with this file, You can obtain one
+// at http://mozilla.org/MPL/2.0/.
+
+// Explanations of the labels of statements in the grounded labelling
+
+package caes
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// The rule of the evaluation which fixed the label of a statement
+type Rule int
+
+const (
+	NoRule            Rule = iota // no rule applied, the statement is undecided
+	AssumedRule                   // the statement is assumed, and thus in
+	AlternativeRule               // another position of the issue is assumed, so the statement is out
+	UnsupportedRule               // the statement has no applicable argument with a weight greater than 0.0, and is out
+	SupportedRule                 // the statement is not at issue and supported, and thus in
+	IssueResolvedRule             // the issue of the statement was resolved by applying its proof standard
+)
+
+func (r Rule) String() string {
+	switch r {
+	case AssumedRule:
+		return "assumed"
+	case AlternativeRule:
+		return "alternative assumed"
+	case UnsupportedRule:
+		return "unsupported"
+	case SupportedRule:
+		return "supported non-issue"
+	case IssueResolvedRule:
+		return "issue resolved"
+	default:
+		return "no rule applied"
+	}
+}
+
+// Records why a statement has its label in the grounded labelling
+type Explanation struct {
+	Statement *Statement
+	Label     Label
+	Rule      Rule
+	// The iteration of the evaluation in which the label was fixed,
+	// where 0 is the initialization with the assumptions
+	Step int
+	// The assumed position, if the rule is AlternativeRule
+	Assumption *Statement
+	// The issue, and the maximum weights of the arguments pro each of its
+	// positions and the winning position, if any, if the rule is
+	// IssueResolvedRule
+	Issue   *Issue
+	Weights map[*Statement]float64
+	Winner  *Statement
+	// The weights of the arguments taken into account, i.e. the arguments
+	// pro the statement or, if an issue was resolved, pro its positions
+	ArgWeights map[*Argument]float64
+	// The undercutters of these arguments, with their labels at the time
+	// the rule was applied
+	Undercutters map[*Statement]Label
+}
+
+type Explanations map[*Statement]*Explanation
+
+// Returns the grounded labelling of an argument graph, as
+// GroundedLabelling, together with an explanation of the label of each
+// statement of the graph.
+func (ag *ArgGraph) ExplainedGroundedLabelling() (Labelling, Explanations) {
+	l := NewLabelling()
+	l.init(ag)
+	ex := Explanations{}
+	for _, stmt := range ag.Statements {
+		e := &Explanation{Statement: stmt, Label: l[stmt]}
+		switch l[stmt] {
+		case In:
+			e.Rule = AssumedRule
+		case Out:
+			e.Rule = AlternativeRule
+			for _, p := range stmt.Issue.Positions {
+				if l[p] == In {
+					e.Assumption = p
+					break
+				}
+			}
+		}
+		ex[stmt] = e
+	}
+	ag.fixpoint(l, ex)
+	return l, ex
+}
+
+// Returns an explanation of the label of the statement, assigned by the
+// rule in the given step, recording the weights and undercutters of the
+// arguments pro the given statements in the labelling l
+func newExplanation(l Labelling, stmt *Statement, label Label, rule Rule, step int, stmts ...*Statement) *Explanation {
+	e := &Explanation{Statement: stmt, Label: label, Rule: rule, Step: step}
+	e.ArgWeights = make(map[*Argument]float64)
+	e.Undercutters = make(map[*Statement]Label)
+	for _, s := range stmts {
+		for _, arg := range s.Args {
+			e.ArgWeights[arg] = arg.GetWeight(l)
+			if arg.Undercutter != nil {
+				e.Undercutters[arg.Undercutter] = l[arg.Undercutter]
+			}
+		}
+	}
+	return e
+}
+
+func sortedStatements(stmts []*Statement) []*Statement {
+	sort.Slice(stmts, func(i, j int) bool { return stmts[i].Id < stmts[j].Id })
+	return stmts
+}
+
+// Writes a textual explanation, with one fact per line
+func (e *Explanation) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "statement: %s\n", e.Statement.Id)
+	if e.Statement.Text != "" {
+		fmt.Fprintf(w, "text: %s\n", e.Statement.Text)
+	}
+	fmt.Fprintf(w, "label: %s\n", e.Label)
+	fmt.Fprintf(w, "rule: %s\n", e.Rule)
+	if e.Rule != NoRule {
+		fmt.Fprintf(w, "step: %d\n", e.Step)
+	}
+	if e.Assumption != nil {
+		fmt.Fprintf(w, "assumption: %s\n", e.Assumption.Id)
+	}
+	if e.Issue != nil {
+		fmt.Fprintf(w, "issue: %s\n", e.Issue.Id)
+		fmt.Fprintf(w, "standard: %s\n", e.Issue.Standard)
+		fmt.Fprintf(w, "position weights:\n")
+		for _, p := range e.Issue.Positions {
+			winner := ""
+			if p == e.Winner {
+				winner = " (winner)"
+			}
+			fmt.Fprintf(w, "    %s: %.2f%s\n", p.Id, e.Weights[p], winner)
+		}
+	}
+	if len(e.ArgWeights) > 0 {
+		ids := []string{}
+		weights := map[string]float64{}
+		for arg, x := range e.ArgWeights {
+			ids = append(ids, arg.Id)
+			weights[arg.Id] = x
+		}
+		sort.Strings(ids)
+		fmt.Fprintf(w, "argument weights:\n")
+		for _, id := range ids {
+			fmt.Fprintf(w, "    %s: %.2f\n", id, weights[id])
+		}
+	}
+	if len(e.Undercutters) > 0 {
+		stmts := []*Statement{}
+		for s := range e.Undercutters {
+			stmts = append(stmts, s)
+		}
+		fmt.Fprintf(w, "undercutters:\n")
+		for _, s := range sortedStatements(stmts) {
+			fmt.Fprintf(w, "    %s: %s\n", s.Id, e.Undercutters[s])
+		}
+	}
+}
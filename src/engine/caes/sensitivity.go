@@ -0,0 +1,187 @@
+// Copyright © 2015 The Carneades Authors
+// This Source Code Form is subject to the terms of the
+// Mozilla Public License, v. 2.0. If a copy of the MPL
+// was not distributed with this file, You can obtain one
+// at http://mozilla.org/MPL/2.0/.
+
+// Sensitivity analysis: which changes of the assumptions of an argument
+// graph change the label of a statement
+
+package caes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/carneades/carneades-4/src/engine/terms"
+)
+
+// A change of the assumptions of an argument graph: the retraction of
+// some assumptions and the addition of others, and the label of a
+// statement in the grounded labelling after the change
+type AssumptionChange struct {
+	Retracted []string // ids of the retracted statements, sorted
+	Added     []string // ids of the assumed statements, sorted
+	Label     Label
+}
+
+// Returns the number of assumptions retracted or added
+func (c AssumptionChange) Size() int {
+	return len(c.Retracted) + len(c.Added)
+}
+
+func (c AssumptionChange) String() string {
+	s := []string{}
+	if len(c.Retracted) > 0 {
+		s = append(s, "retract "+strings.Join(c.Retracted, ", "))
+	}
+	if len(c.Added) > 0 {
+		s = append(s, "assume "+strings.Join(c.Added, ", "))
+	}
+	return fmt.Sprintf("%s: %s", c.Label, strings.Join(s, "; "))
+}
+
+// Returns the statements the label of the statement depends on, directly
+// or indirectly, including the statement, sorted by id
+func ancestors(s *Statement) []*Statement {
+	visited := map[*Statement]bool{s: true}
+	queue := []*Statement{s}
+	for len(queue) > 0 {
+		t := queue[0]
+		queue = queue[1:]
+		for _, d := range dependencies(t) {
+			if !visited[d] {
+				visited[d] = true
+				queue = append(queue, d)
+			}
+		}
+	}
+	result := []*Statement{}
+	for t := range visited {
+		result = append(result, t)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Id < result[j].Id })
+	return result
+}
+
+// Enumerates the subsets of {0, ..., n-1} with at most max members, by
+// increasing size and, for each size, in lexicographic order, calling ok
+// with the members of each subset in increasing order. Supersets of the
+// subsets for which ok returns true are skipped, so that ok returns true
+// only for minimal subsets. The number of subsets is exponential in max.
+// The enumeration stops when done is closed. A nil channel is never
+// closed.
+func minimalSubsets(done <-chan struct{}, n, max int, ok func(chosen []int) bool) {
+	found := [][]int{} // the subsets for which ok returned true
+	// Is some subset found a subset of the chosen members?
+	subsumed := func(chosen []int) bool {
+		for _, f := range found {
+			i := 0
+			for _, c := range chosen {
+				if i < len(f) && f[i] == c {
+					i++
+				}
+			}
+			if i == len(f) {
+				return true
+			}
+		}
+		return false
+	}
+	// enumerate the subsets of k members
+	stopped := false
+	var choose func(k, start int, chosen []int)
+	choose = func(k, start int, chosen []int) {
+		if stopped {
+			return
+		}
+		if len(chosen) == k {
+			select {
+			case <-done:
+				stopped = true
+				return
+			default:
+			}
+			if !subsumed(chosen) && ok(chosen) {
+				found = append(found, append([]int{}, chosen...))
+			}
+			return
+		}
+		for i := start; i <= n-(k-len(chosen)); i++ {
+			choose(k, i+1, append(chosen, i))
+		}
+	}
+	for k := 1; k <= max && k <= n; k++ {
+		choose(k, 0, []int{})
+	}
+}
+
+// Returns the minimal changes of the assumptions of the argument graph,
+// retracting assumptions or assuming further statements, which change
+// the label of the statement with the given id in the grounded labelling.
+// Only the statements on which the label of the statement depends are
+// considered, and only changes of at most max assumptions, to bound the
+// search, which is exponential in max. Assuming the statement itself, and
+// changes resulting in inconsistent assumptions, i.e. assuming several
+// positions of an issue, are excluded. A change is minimal if no change
+// with a subset of its retractions and additions changes the label of
+// the statement too. The changes are ordered by size. The assumptions of
+// the graph are restored before returning.
+func (ag *ArgGraph) Sensitivity(id string, max int) ([]AssumptionChange, error) {
+	l := ag.GroundedLabelling() // normalizes the ids of the statements
+	target, ok := ag.Statements[terms.Normalize(id)]
+	if !ok {
+		return nil, fmt.Errorf("unknown statement: %s", id)
+	}
+	assumptions := ag.Assumptions
+	defer func() {
+		ag.Assumptions = assumptions
+		ag.assums = SliceToMap(assumptions)
+	}()
+	assumed := SliceToMap(assumptions)
+
+	// the candidates for retraction or addition, excluding the addition
+	// of the statement itself
+	candidates := []*Statement{}
+	for _, s := range ancestors(target) {
+		if s != target || assumed[terms.Normalize(s.Id)] {
+			candidates = append(candidates, s)
+		}
+	}
+
+	changes := []AssumptionChange{}
+	minimalSubsets(nil, len(candidates), max, func(chosen []int) bool {
+		c := AssumptionChange{Retracted: []string{}, Added: []string{}}
+		changed := map[string]bool{}
+		for _, i := range chosen {
+			s := candidates[i]
+			changed[terms.Normalize(s.Id)] = true
+			if assumed[terms.Normalize(s.Id)] {
+				c.Retracted = append(c.Retracted, s.Id)
+			} else {
+				c.Added = append(c.Added, s.Id)
+			}
+		}
+		as := []string{}
+		for _, a := range assumptions {
+			if !changed[a] {
+				as = append(as, a)
+			}
+		}
+		for _, a := range c.Added {
+			as = append(as, terms.Normalize(a))
+		}
+		ag.Assumptions = as
+		ag.assums = SliceToMap(as)
+		if ag.Inconsistent() {
+			return false
+		}
+		if c.Label = ag.GroundedLabelling()[target]; c.Label != l[target] {
+			changes = append(changes, c)
+			return true
+		}
+		return false
+	})
+	return changes, nil
+}
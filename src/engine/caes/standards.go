@@ -0,0 +1,185 @@
+// Copyright © 2015 The Carneades Authors
+// This Source Code Form is subject to the terms of the
+// Mozilla Public License, v. 2.0. If a copy of the MPL
+// was not distributed with this file, You can obtain one
+// at http://mozilla.org/MPL/2.0/.
+
+package caes
+
+// types and procedures for proof standards
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// A proof standard of an issue, referring to a registered definition of
+// a proof standard by name, such as "pe", "cce" or "brd", with values
+// for some of the parameters of the definition. The other parameters have
+// their default values. The zero value, with an empty name, is the
+// preponderance of the evidence standard.
+type Standard struct {
+	Name       string // lower case
+	Parameters map[string]float64
+}
+
+// The definition of a proof standard. Greater checks whether w1, the
+// maximum weight of the arguments pro some position of an issue, is
+// sufficiently greater than w2, the maximum weight of the arguments pro
+// some other position, for the position to win, given the values of all
+// the parameters of the standard. A position wins if it is supported by
+// some argument with a weight greater than 0.0 and sufficiently greater
+// than every other position. If several positions win, the first of them
+// in the list of the positions of the issue is labelled In.
+type ProofStandard struct {
+	Parameters map[string]float64 // the parameters and their default values
+	Greater    func(w1, w2 float64, parameters map[string]float64) bool
+}
+
+// The registered proof standards, by name, guarded by standardsMu, since
+// standards may be registered while argument graphs are being evaluated.
+// Use RegisterStandard to register further proof standards.
+var standardsMu sync.RWMutex
+
+var proofStandards = map[string]*ProofStandard{
+	// preponderance of the evidence
+	"pe": &ProofStandard{
+		Parameters: map[string]float64{},
+		Greater: func(w1, w2 float64, p map[string]float64) bool {
+			return w1 > w2
+		},
+	},
+	// clear and convincing evidence
+	"cce": &ProofStandard{
+		Parameters: map[string]float64{"alpha": 0.5},
+		Greater: func(w1, w2 float64, p map[string]float64) bool {
+			return w1 > w2 && w1-w2 > p["alpha"]
+		},
+	},
+	// beyond reasonable doubt
+	"brd": &ProofStandard{
+		Parameters: map[string]float64{"alpha": 0.5, "beta": 0.3},
+		Greater: func(w1, w2 float64, p map[string]float64) bool {
+			return w1 > w2 && w1-w2 > p["alpha"] && w2 < p["beta"]
+		},
+	},
+	// scintilla of evidence: some argument pro the position at
+	// least as strong as the arguments pro the other position
+	"se": &ProofStandard{
+		Parameters: map[string]float64{},
+		Greater: func(w1, w2 float64, p map[string]float64) bool {
+			return w1 >= w2
+		},
+	},
+	// dialectical validity: no argument pro the other position with a
+	// weight greater than 0.0
+	"dv": &ProofStandard{
+		Parameters: map[string]float64{},
+		Greater: func(w1, w2 float64, p map[string]float64) bool {
+			return w2 <= 0.0
+		},
+	},
+}
+
+// The predefined standards, without parameters. These are values; use
+// Equal to compare standards, since Standard is not comparable with ==.
+var (
+	PE  = Standard{Name: "pe"}  // preponderance of the evidence
+	CCE = Standard{Name: "cce"} // clear and convincing evidence
+	BRD = Standard{Name: "brd"} // beyond reasonable doubt
+	SE  = Standard{Name: "se"}  // scintilla of evidence
+	DV  = Standard{Name: "dv"}  // dialectical validity
+)
+
+// Registers a proof standard under the given name, which is case
+// insensitive, replacing any standard registered under the name.
+// It is safe to register standards concurrently with evaluations.
+func RegisterStandard(name string, ps *ProofStandard) {
+	standardsMu.Lock()
+	defer standardsMu.Unlock()
+	proofStandards[strings.ToLower(name)] = ps
+}
+
+// Returns the standard with the given name and parameter values
+func NewStandard(name string, parameters map[string]float64) Standard {
+	return Standard{Name: strings.ToLower(name), Parameters: parameters}
+}
+
+// Returns the name of the standard in lower case, "pe" if it is empty
+func (std Standard) name() string {
+	if std.Name == "" {
+		return PE.Name
+	}
+	return strings.ToLower(std.Name)
+}
+
+// Returns the definition of the standard, if it has been registered
+func (std Standard) Definition() (*ProofStandard, bool) {
+	standardsMu.RLock()
+	defer standardsMu.RUnlock()
+	ps, ok := proofStandards[std.name()]
+	return ps, ok
+}
+
+// Checks whether two standards have the same name, ignoring case, with
+// the zero value being PE, and the same parameter values. Parameters
+// given the default value of the definition explicitly are not ignored.
+func (std Standard) Equal(other Standard) bool {
+	if std.name() != other.name() || len(std.Parameters) != len(other.Parameters) {
+		return false
+	}
+	for k, v := range std.Parameters {
+		if w, ok := other.Parameters[k]; !ok || v != w {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the names of the parameters of the standard, sorted
+func (std Standard) parameterNames() []string {
+	names := []string{}
+	for k := range std.Parameters {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Returns the name of the standard in upper case, followed by the values
+// of its parameters, if any, e.g. CCE{alpha: 0.4}
+func (std Standard) String() string {
+	s := strings.ToUpper(std.name())
+	if len(std.Parameters) == 0 {
+		return s
+	}
+	for i, k := range std.parameterNames() {
+		if i == 0 {
+			s += "{"
+		} else {
+			s += ", "
+		}
+		s += fmt.Sprintf("%s: %v", k, std.Parameters[k])
+	}
+	return s + "}"
+}
+
+// Apply a proof standard to check whether w1 is sufficiently greater
+// than w2, where w1 and w2 are argument weights. Unregistered standards
+// are never met.
+func (std Standard) greater(w1, w2 float64) bool {
+	ps, ok := std.Definition()
+	if !ok {
+		return false
+	}
+	p := make(map[string]float64)
+	for k, v := range ps.Parameters {
+		p[k] = v
+	}
+	for k, v := range std.Parameters {
+		p[k] = v
+	}
+	return ps.Greater(w1, w2, p)
+}
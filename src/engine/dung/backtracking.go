@@ -0,0 +1,1268 @@
+// Copyright © 2015 The Carneades Authors
+// This Source Code Form is subject to the terms of the
+// Mozilla Public License, v. 2.0. If a copy of the MPL
+// was not distributed with this file, You can obtain one
+// at http://mozilla.org/MPL/2.0/.
+
+// Labelling-based backtracking solver for Dung AFs.
+//
+// Each argument has a domain of possible labels (in, out, undecided).
+// The conditions of complete labellings are used as constraints to
+// narrow the domains, until a fixpoint is reached, and the search
+// branches on the label of some argument whose label is not yet fixed.
+// Complete labellings correspond one-to-one to complete extensions.
+//
+// As in CDCL SAT solvers, nogoods are learned from the conflicts found,
+// the search backjumps over decisions not involved in a conflict, and
+// the arguments involved in recent conflicts are branched on first.
+
+package dung
+
+import "sort"
+
+// label domains, as bit sets
+const (
+	dIn uint8 = 1 << iota
+	dOut
+	dUndec
+)
+
+const dAny = dIn | dOut | dUndec
+
+// An index-based representation of an AF. Attackers which are
+// not arguments of the AF are included as external nodes, which
+// can never be in an extension. External nodes follow the
+// arguments of the AF in the args slice.
+type graph struct {
+	args      []Arg
+	index     map[Arg]int
+	attackers [][]int
+	targets   [][]int
+	external  []bool
+	n         int             // the number of arguments of the AF, excluding external nodes
+	done      <-chan struct{} // if closed, searches are cancelled
+}
+
+func newGraph(af *AF) *graph {
+	g := &graph{index: make(map[Arg]int)}
+	add := func(arg Arg, external bool) int {
+		if i, found := g.index[arg]; found {
+			return i
+		}
+		i := len(g.args)
+		g.index[arg] = i
+		g.args = append(g.args, arg)
+		g.external = append(g.external, external)
+		g.attackers = append(g.attackers, []int{})
+		g.targets = append(g.targets, []int{})
+		return i
+	}
+	for _, arg := range af.args {
+		add(arg, false)
+	}
+	g.n = len(g.args)
+	// Follow the attack relation backwards from the arguments of the AF,
+	// to find external attackers, and the attackers of these, transitively.
+	for i := 0; i < len(g.args); i++ {
+		seen := make(map[int]bool)
+		for _, atk := range af.atks[g.args[i]] {
+			j := add(atk, true)
+			if seen[j] {
+				continue // ignore duplicate attacks
+			}
+			seen[j] = true
+			g.attackers[i] = append(g.attackers[i], j)
+			g.targets[j] = append(g.targets[j], i)
+		}
+	}
+	return g
+}
+
+// The kinds of labellings searched for. The labellings of each kind
+// correspond one-to-one to the sets of arguments labelled in.
+type mode int
+
+const (
+	completeMode     mode = iota // complete labellings
+	stableMode                   // complete labellings without undecided arguments
+	admissibleMode               // admissible labellings, with out iff some attacker is in
+	conflictFreeMode             // conflict-free labellings, with out iff some attacker is in
+)
+
+// A literal, denoting the removal of a label from the domain of a node.
+// The removal of the label with the bit index k from the domain of the
+// node with index i is the literal 3*i + k.
+type literal int32
+
+var labels = [3]uint8{dIn, dOut, dUndec}
+
+func removal(i int, v uint8) literal {
+	switch v {
+	case dIn:
+		return literal(3 * i)
+	case dOut:
+		return literal(3*i + 1)
+	default:
+		return literal(3*i + 2)
+	}
+}
+
+func (l literal) node() int {
+	return int(l) / 3
+}
+
+func (l literal) label() uint8 {
+	return labels[l%3]
+}
+
+// The removal of a label, with its reason, the removals which implied it,
+// stored in the reasons of the search from start to end. Decisions have
+// no reason.
+type trailEntry struct {
+	lit        literal
+	start, end int32
+}
+
+// A nogood watching a literal, with some other removal of the nogood,
+// the blocker, such that the nogood holds if the label of the blocker is
+// assigned.
+type watch struct {
+	nogood  int
+	blocker literal
+}
+
+// The state of a backtracking search.
+//
+// Every narrowing of a domain is recorded on the trail as the removal of
+// labels, together with its reason. When a conflict is found, the
+// removals of the conflict are resolved with their reasons, until a
+// single removal of the last decision level remains. The resulting set
+// of removals, which cannot all hold in any labelling, is learned as a
+// nogood, and the search backjumps to the greatest earlier level of these
+// removals. Nogoods are checked using two watched removals per nogood.
+type search struct {
+	g        *graph
+	mode     mode
+	dom      []uint8
+	trail    []trailEntry
+	queue    []int
+	head     int // the index of the next node of the queue
+	inQueue  []bool
+	levels   []int       // the length of the trail at the start of each decision level
+	level    []int32     // the decision level of each literal, if its label has been removed
+	pos      []int32     // the index of the trail entry of each literal, if its label has been removed
+	reasons  []literal   // the reasons of the trail entries
+	nogoods  [][]literal // sets of removals which cannot all hold
+	lbd      []int       // of each learned nogood, the number of its decision levels, or 0 if not learned
+	learned  int         // the number of learned nogoods
+	watches  [][]watch   // the nogoods watching each literal
+	checked  int         // the trail entries checked against the nogoods
+	conflict []literal   // the removals of the last conflict found
+	failed   bool        // true if the search has no labellings
+	activity []float64   // of the arguments, for selecting the argument to branch on
+	bump     float64     // the amount by which activities are increased
+	phase    []uint8     // the last label of each argument, tried first
+	seen     []bool      // of literals, for analyzing conflicts
+	buf      []literal   // for constructing reasons
+}
+
+func newSearch(g *graph, m mode) *search {
+	s := &search{
+		g:        g,
+		mode:     m,
+		dom:      make([]uint8, len(g.args)),
+		inQueue:  make([]bool, len(g.args)),
+		level:    make([]int32, 3*len(g.args)),
+		pos:      make([]int32, 3*len(g.args)),
+		watches:  make([][]watch, 3*len(g.args)),
+		seen:     make([]bool, 3*len(g.args)),
+		activity: make([]float64, len(g.args)),
+		phase:    make([]uint8, len(g.args)),
+		bump:     1,
+	}
+	for i := range g.args {
+		switch {
+		case g.external[i]:
+			s.dom[i] = dOut | dUndec
+		case m == stableMode:
+			s.dom[i] = dIn | dOut
+		default:
+			s.dom[i] = dAny
+		}
+		// the most constrained arguments, with the most attackers and
+		// targets, are branched on first, until conflicts are found
+		s.activity[i] = float64(len(g.attackers[i]) + len(g.targets[i]))
+		s.enqueue(i)
+	}
+	return s
+}
+
+func (s *search) enqueue(i int) {
+	if !s.inQueue[i] {
+		s.inQueue[i] = true
+		s.queue = append(s.queue, i)
+	}
+}
+
+// Remove the labels in v from the domain of a node, given the reason for
+// the removal. Returns false, recording the conflict, if the domain
+// becomes empty.
+func (s *search) remove(i int, v uint8, reason []literal) bool {
+	v &= s.dom[i]
+	if v == 0 {
+		return true
+	}
+	if v == s.dom[i] {
+		s.conflict = append(s.conflict[:0], reason...)
+		for _, w := range labels {
+			if s.dom[i]&w == 0 {
+				s.conflict = append(s.conflict, removal(i, w))
+			}
+		}
+		return false
+	}
+	start := int32(len(s.reasons))
+	s.reasons = append(s.reasons, reason...)
+	end := int32(len(s.reasons))
+	for _, w := range labels {
+		if v&w != 0 {
+			l := removal(i, w)
+			s.level[l] = int32(len(s.levels))
+			s.pos[l] = int32(len(s.trail))
+			s.trail = append(s.trail, trailEntry{l, start, end})
+		}
+	}
+	s.dom[i] &^= v
+	if d := s.dom[i]; d == dIn || d == dOut || d == dUndec {
+		s.phase[i] = d
+	}
+	s.enqueue(i)
+	for _, t := range s.g.targets[i] {
+		s.enqueue(t)
+	}
+	return true
+}
+
+// Narrow the domain of a node, before searching. Returns false if the
+// domain becomes empty, in which case the search has no labellings.
+func (s *search) set(i int, d uint8) bool {
+	if !s.remove(i, s.dom[i]&^d, nil) {
+		s.failed = true
+	}
+	return !s.failed
+}
+
+// Undo all changes to the domains made since the trail had the given length.
+func (s *search) undo(mark int) {
+	if mark >= len(s.trail) {
+		return
+	}
+	for k := len(s.trail) - 1; k >= mark; k-- {
+		l := s.trail[k].lit
+		s.dom[l.node()] |= l.label()
+	}
+	s.reasons = s.reasons[:s.trail[mark].start]
+	s.trail = s.trail[:mark]
+	if s.checked > mark {
+		s.checked = mark
+	}
+}
+
+// Undo the decisions made after the given decision level
+func (s *search) backjump(level int) {
+	if level < len(s.levels) {
+		s.undo(s.levels[level])
+		s.levels = s.levels[:level]
+	}
+}
+
+// Returns true if the label of the literal has been removed
+func (s *search) removed(l literal) bool {
+	return s.dom[l.node()]&l.label() == 0
+}
+
+// Appends to r the removals of the label v from the domains of the
+// given nodes, except the node except
+func appendRemovals(r []literal, nodes []int, v uint8, except int) []literal {
+	for _, b := range nodes {
+		if b != except {
+			r = append(r, removal(b, v))
+		}
+	}
+	return r
+}
+
+// Appends to r the removals labelling the node i with the label v
+func appendLabelled(r []literal, i int, v uint8) []literal {
+	for _, w := range labels {
+		if w != v {
+			r = append(r, removal(i, w))
+		}
+	}
+	return r
+}
+
+// Apply the constraint of a node, relating its label to the labels
+// of its attackers. Returns false if a conflict is found.
+func (s *search) revise(a int) bool {
+	attackers := s.g.attackers[a]
+	notOut := -1 // some attacker which cannot be out
+	in := -1     // some attacker which must be in
+	canBeIn, lastCanBeIn := 0, -1
+	canBeUndec, lastCanBeUndec := 0, -1
+	canBeNotOut, lastCanBeNotOut := 0, -1
+	for _, b := range attackers {
+		d := s.dom[b]
+		if d != dOut {
+			canBeNotOut++
+			lastCanBeNotOut = b
+		}
+		if d&dOut == 0 {
+			notOut = b
+		}
+		if d == dIn {
+			in = b
+		}
+		if d&dIn != 0 {
+			canBeIn++
+			lastCanBeIn = b
+		}
+		if d&dUndec != 0 {
+			canBeUndec++
+			lastCanBeUndec = b
+		}
+	}
+
+	// In all modes, an argument is out iff some attacker is in.
+	if canBeIn == 0 && s.dom[a]&dOut != 0 && !s.remove(a, dOut, appendRemovals(s.buf[:0], attackers, dIn, -1)) {
+		return false
+	}
+	if in >= 0 && s.dom[a]&dUndec != 0 && !s.remove(a, dUndec, appendLabelled(s.buf[:0], in, dIn)) {
+		return false
+	}
+	switch {
+	case s.g.external[a]:
+		// External nodes are never in.
+	case s.mode == completeMode || s.mode == stableMode:
+		// in iff all attackers are out, and
+		// undecided iff no attacker is in and not all attackers are out
+		if notOut >= 0 && s.dom[a]&dIn != 0 && !s.remove(a, dIn, append(s.buf[:0], removal(notOut, dOut))) {
+			return false
+		}
+		if canBeUndec == 0 && s.dom[a]&dUndec != 0 && !s.remove(a, dUndec, appendRemovals(s.buf[:0], attackers, dUndec, -1)) {
+			return false
+		}
+	case s.mode == admissibleMode:
+		// in only if all attackers are out
+		if notOut >= 0 && s.dom[a]&dIn != 0 && !s.remove(a, dIn, append(s.buf[:0], removal(notOut, dOut))) {
+			return false
+		}
+	case s.mode == conflictFreeMode:
+		// in only if no attacker is in
+		if in >= 0 && s.dom[a]&dIn != 0 && !s.remove(a, dIn, appendLabelled(s.buf[:0], in, dIn)) {
+			return false
+		}
+	}
+
+	// Narrow the domains of the attackers
+	d := s.dom[a]
+	if d&dOut == 0 {
+		// no attacker may be in
+		for _, b := range attackers {
+			if s.dom[b]&dIn != 0 && !s.remove(b, dIn, append(s.buf[:0], removal(a, dOut))) {
+				return false
+			}
+		}
+	}
+	if d&dIn == 0 && s.mode == completeMode && !s.g.external[a] && canBeNotOut == 1 && s.dom[lastCanBeNotOut]&dOut != 0 {
+		// some attacker is not out, since a is not in
+		r := append(s.buf[:0], removal(a, dIn))
+		for _, b := range attackers {
+			if b != lastCanBeNotOut {
+				r = appendLabelled(r, b, dOut)
+			}
+		}
+		if !s.remove(lastCanBeNotOut, dOut, r) {
+			return false
+		}
+	}
+	switch d {
+	case dIn:
+		if s.mode != conflictFreeMode {
+			// all attackers are out
+			for _, b := range attackers {
+				if s.dom[b]&dUndec != 0 && !s.remove(b, dUndec, appendLabelled(s.buf[:0], a, dIn)) {
+					return false
+				}
+			}
+		}
+	case dOut:
+		if canBeIn == 1 && s.dom[lastCanBeIn] != dIn {
+			r := appendLabelled(s.buf[:0], a, dOut)
+			r = appendRemovals(r, attackers, dIn, lastCanBeIn)
+			if !s.remove(lastCanBeIn, dOut|dUndec, r) {
+				return false
+			}
+		}
+	case dUndec:
+		if s.mode == completeMode && !s.g.external[a] && canBeUndec == 1 && s.dom[lastCanBeUndec] != dUndec {
+			r := appendLabelled(s.buf[:0], a, dUndec)
+			r = appendRemovals(r, attackers, dUndec, lastCanBeUndec)
+			if !s.remove(lastCanBeUndec, dIn|dOut, r) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Adds a nogood, watching its first two removals. If the nogood has a
+// single removal, its label is assigned, at the current decision level.
+// The lbd of learned nogoods is greater than 0.
+func (s *search) addNogood(c []literal, lbd int) {
+	if len(c) == 1 {
+		s.remove(c[0].node(), s.dom[c[0].node()]&^c[0].label(), nil)
+		return
+	}
+	k := len(s.nogoods)
+	s.nogoods = append(s.nogoods, c)
+	s.lbd = append(s.lbd, lbd)
+	if lbd > 0 {
+		s.learned++
+	}
+	s.watches[c[0]] = append(s.watches[c[0]], watch{k, c[1]})
+	s.watches[c[1]] = append(s.watches[c[1]], watch{k, c[0]})
+}
+
+// Delete half of the learned nogoods, keeping those with the fewest
+// decision levels, and then the fewest removals, which are the most
+// likely to be used again. Nogoods with at most two decision levels are
+// kept. Must be called at decision level 0. Since the reasons of removals
+// are copied to the trail, deleting nogoods does not affect the trail.
+func (s *search) reduceNogoods() {
+	candidates := []int{}
+	for k, lbd := range s.lbd {
+		if lbd > 2 {
+			candidates = append(candidates, k)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if s.lbd[a] != s.lbd[b] {
+			return s.lbd[a] > s.lbd[b]
+		}
+		return len(s.nogoods[a]) > len(s.nogoods[b])
+	})
+	deleted := make([]bool, len(s.nogoods))
+	for _, k := range candidates[:len(candidates)/2] {
+		deleted[k] = true
+	}
+	nogoods, lbd := s.nogoods[:0], s.lbd[:0]
+	s.learned = 0
+	for k, c := range s.nogoods {
+		if deleted[k] {
+			continue
+		}
+		nogoods = append(nogoods, c)
+		lbd = append(lbd, s.lbd[k])
+		if s.lbd[k] > 0 {
+			s.learned++
+		}
+	}
+	s.nogoods, s.lbd = nogoods, lbd
+	for l := range s.watches {
+		s.watches[l] = s.watches[l][:0]
+	}
+	for k, c := range s.nogoods {
+		s.watches[c[0]] = append(s.watches[c[0]], watch{k, c[1]})
+		s.watches[c[1]] = append(s.watches[c[1]], watch{k, c[0]})
+	}
+}
+
+// Before searching, require some node i with M[i] false to have a label
+// in the domain d.
+func (s *search) require(M []bool, d uint8) {
+	c := []literal{}
+	for i := 0; i < s.g.n; i++ {
+		if M[i] {
+			continue
+		}
+		for _, v := range labels {
+			if d&v != 0 && !s.removed(removal(i, v)) {
+				c = append(c, removal(i, v))
+			}
+		}
+	}
+	if len(c) == 0 {
+		s.failed = true
+		return
+	}
+	s.addNogood(c, 0)
+}
+
+// Check the nogoods watching the literal, whose label has been removed.
+// Returns false if a conflict is found.
+func (s *search) checkNogoods(p literal) bool {
+	ws := s.watches[p]
+	j := 0
+	for k := 0; k < len(ws); k++ {
+		w := ws[k]
+		if s.dom[w.blocker.node()] == w.blocker.label() {
+			ws[j] = w
+			j++
+			continue
+		}
+		c := s.nogoods[w.nogood]
+		if c[0] == p {
+			c[0], c[1] = c[1], c[0]
+		}
+		w.blocker = c[0]
+		if s.dom[c[0].node()] == c[0].label() {
+			// the label of c[0] is assigned, so the nogood holds
+			ws[j] = w
+			j++
+			continue
+		}
+		moved := false
+		for m := 2; m < len(c); m++ {
+			if !s.removed(c[m]) {
+				c[1], c[m] = c[m], c[1]
+				s.watches[c[1]] = append(s.watches[c[1]], w)
+				moved = true
+				break
+			}
+		}
+		if moved {
+			continue
+		}
+		ws[j] = w
+		j++
+		if s.removed(c[0]) {
+			s.conflict = append(s.conflict[:0], c...)
+			j += copy(ws[j:], ws[k+1:])
+			s.watches[p] = ws[:j]
+			return false
+		}
+		// all removals but c[0] hold, so its label is assigned
+		s.remove(c[0].node(), s.dom[c[0].node()]&^c[0].label(), c[1:])
+	}
+	s.watches[p] = ws[:j]
+	return true
+}
+
+// Propagate the constraints until a fixpoint is reached.
+// Returns false if a conflict is found.
+func (s *search) propagate() bool {
+	for {
+		for s.checked < len(s.trail) {
+			p := s.trail[s.checked].lit
+			s.checked++
+			if !s.checkNogoods(p) {
+				s.clearQueue()
+				return false
+			}
+		}
+		if s.head == len(s.queue) {
+			s.queue, s.head = s.queue[:0], 0
+			return true
+		}
+		a := s.queue[s.head]
+		s.head++
+		s.inQueue[a] = false
+		if !s.revise(a) {
+			s.clearQueue()
+			return false
+		}
+	}
+}
+
+func (s *search) clearQueue() {
+	for _, b := range s.queue[s.head:] {
+		s.inQueue[b] = false
+	}
+	s.queue, s.head = s.queue[:0], 0
+}
+
+// Returns the greatest decision level of the literals
+func (s *search) maxLevel(c []literal) int {
+	max := 0
+	for _, l := range c {
+		if int(s.level[l]) > max {
+			max = int(s.level[l])
+		}
+	}
+	return max
+}
+
+// Increase the activity of a node occurring in a conflict
+func (s *search) bumpActivity(i int) {
+	s.activity[i] += s.bump
+	if s.activity[i] > 1e100 {
+		for j := range s.activity {
+			s.activity[j] *= 1e-100
+		}
+		s.bump *= 1e-100
+	}
+}
+
+// Derive a nogood from the conflict, whose removals all occurred at the
+// current decision level or before, by resolving removals of the current
+// level with their reasons, until a single removal of this level remains.
+// This removal is the first of the nogood, followed by a removal of the
+// greatest earlier level, if any.
+func (s *search) analyze() []literal {
+	level := int32(len(s.levels))
+	learned := []literal{0}
+	count := 0 // the removals of the current level to be resolved
+	add := func(l literal) {
+		if s.seen[l] || s.level[l] == 0 {
+			return
+		}
+		s.seen[l] = true
+		s.bumpActivity(l.node())
+		if s.level[l] == level {
+			count++
+		} else {
+			learned = append(learned, l)
+		}
+	}
+	for _, l := range s.conflict {
+		add(l)
+	}
+	k := len(s.trail) - 1
+	for {
+		for !s.seen[s.trail[k].lit] {
+			k--
+		}
+		e := s.trail[k]
+		k--
+		s.seen[e.lit] = false
+		count--
+		if count == 0 {
+			learned[0] = e.lit
+			break
+		}
+		for _, l := range s.reasons[e.start:e.end] {
+			add(l)
+		}
+	}
+	// remove the removals implied by the other removals of the nogood
+	abstract := uint32(0)
+	for _, l := range learned[1:] {
+		abstract |= 1 << uint(s.level[l]&31)
+	}
+	cleared := append([]literal{}, learned[1:]...)
+	n := 1
+	for _, l := range learned[1:] {
+		if !s.redundant(l, abstract, &cleared) {
+			learned[n] = l
+			n++
+		}
+	}
+	for _, l := range cleared {
+		s.seen[l] = false
+	}
+	learned = learned[:n]
+	for j := range learned[1:] {
+		if s.level[learned[j+1]] > s.level[learned[1]] {
+			learned[1], learned[j+1] = learned[j+1], learned[1]
+		}
+	}
+	s.bump /= 0.95
+	return learned
+}
+
+// Returns true if the removal l, which is seen, is implied by the other
+// removals seen, via the reasons of the removals. The removals seen
+// while checking this are appended to cleared. Only removals of levels
+// in the set abstract, of levels modulo 32, may be implied.
+func (s *search) redundant(l literal, abstract uint32, cleared *[]literal) bool {
+	e := s.trail[s.pos[l]]
+	if e.start == e.end {
+		return false // a decision
+	}
+	stack := []literal{l}
+	top := len(*cleared)
+	for len(stack) > 0 {
+		e := s.trail[s.pos[stack[len(stack)-1]]]
+		stack = stack[:len(stack)-1]
+		for _, r := range s.reasons[e.start:e.end] {
+			if s.seen[r] || s.level[r] == 0 {
+				continue
+			}
+			f := s.trail[s.pos[r]]
+			if f.start == f.end || abstract&(1<<uint(s.level[r]&31)) == 0 {
+				for _, c := range (*cleared)[top:] {
+					s.seen[c] = false
+				}
+				*cleared = (*cleared)[:top]
+				return false
+			}
+			s.seen[r] = true
+			stack = append(stack, r)
+			*cleared = append(*cleared, r)
+		}
+	}
+	return true
+}
+
+// Learn a nogood from the conflict, backjump and assign the label
+// implied by the nogood. Returns false if the conflict does not depend
+// on any decision, so that there are no further labellings.
+func (s *search) learn() bool {
+	level := s.maxLevel(s.conflict)
+	if level == 0 {
+		s.failed = true
+		return false
+	}
+	s.backjump(level)
+	c := s.analyze()
+	if len(c) == 1 {
+		s.backjump(0)
+	} else {
+		s.backjump(int(s.level[c[1]]))
+	}
+	levels := map[int32]bool{}
+	for _, l := range c {
+		levels[s.level[l]] = true
+	}
+	s.addNogood(c, len(levels))
+	s.remove(c[0].node(), s.dom[c[0].node()]&^c[0].label(), c[1:])
+	return true
+}
+
+// Exclude the labelling found, all of whose domains are fixed, from the
+// further search. Since labellings correspond one-to-one to the sets of
+// arguments labelled in, these sets are compared. Returns false if there
+// are no further labellings.
+func (s *search) block() bool {
+	c := []literal{}
+	for i := 0; i < s.g.n; i++ {
+		if s.dom[i] == dIn {
+			c = appendLabelled(c, i, dIn)
+		} else {
+			c = append(c, removal(i, dIn))
+		}
+	}
+	// order the removals by decreasing decision level
+	for k := 0; k < 2 && k < len(c); k++ {
+		for j := k + 1; j < len(c); j++ {
+			if s.level[c[j]] > s.level[c[k]] {
+				c[k], c[j] = c[j], c[k]
+			}
+		}
+	}
+	if len(c) == 0 || s.level[c[0]] == 0 {
+		s.failed = true
+		return false
+	}
+	if len(c) == 1 || s.level[c[0]] > s.level[c[1]] {
+		// all removals but c[0] hold at the level of c[1]
+		if len(c) == 1 {
+			s.backjump(0)
+		} else {
+			s.backjump(int(s.level[c[1]]))
+		}
+		s.addNogood(c, 0)
+		if len(c) > 1 {
+			s.remove(c[0].node(), s.dom[c[0].node()]&^c[0].label(), c[1:])
+		}
+		return true
+	}
+	s.backjump(int(s.level[c[0]]) - 1)
+	s.addNogood(c, 0)
+	return true
+}
+
+// Returns the element i of the Luby sequence 1, 1, 2, 1, 1, 2, 4, ...,
+// starting with i = 1, used to schedule restarts
+func luby(i int) int {
+	for k := uint(1); ; k++ {
+		if i == 1<<k-1 {
+			return 1 << (k - 1)
+		}
+		if i < 1<<k-1 {
+			return luby(i - 1<<(k-1) + 1)
+		}
+	}
+}
+
+// Returns true if searches in the graph have been cancelled
+func (g *graph) cancelled() bool {
+	select {
+	case <-g.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Select the argument to branch on, the one with the greatest activity
+// whose label is not yet fixed. Returns -1 if all labels are fixed.
+func (s *search) branch() int {
+	next := -1
+	for i := 0; i < s.g.n; i++ {
+		if d := s.dom[i]; d != dIn && d != dOut && d != dUndec {
+			if next < 0 || s.activity[i] > s.activity[next] {
+				next = i
+			}
+		}
+	}
+	return next
+}
+
+// Search for labellings, calling visit for each labelling found. The
+// search stops when visit returns true, or the search is cancelled, in
+// which case true is returned.
+//
+// The search branches on the argument selected by branch, removing
+// undecided from its domain first, so that labellings with more
+// arguments in are found first. The search is restarted after a number
+// of conflicts following the Luby sequence, keeping the nogoods learned.
+func (s *search) solve(visit func() bool) bool {
+	if s.failed || !s.propagate() {
+		s.failed = true
+		return false
+	}
+	restarts, conflicts := 1, 0
+	maxLearned := 2000 + s.g.n
+	for steps := 0; ; steps++ {
+		if steps%64 == 0 && s.g.cancelled() {
+			return true
+		}
+		if !s.propagate() {
+			if !s.learn() {
+				return false
+			}
+			conflicts++
+			continue
+		}
+		if conflicts >= 100*luby(restarts) {
+			restarts++
+			conflicts = 0
+			s.backjump(0)
+			if s.learned > maxLearned {
+				s.reduceNogoods()
+				maxLearned += maxLearned / 10
+			}
+			continue
+		}
+		i := s.branch()
+		if i < 0 {
+			if visit() {
+				return true
+			}
+			if !s.block() {
+				return false
+			}
+			continue
+		}
+		s.levels = append(s.levels, len(s.trail))
+		// remove a label other than the last label of the argument,
+		// preferring labellings with more arguments in
+		for _, v := range []uint8{dUndec, dOut, dIn} {
+			if s.dom[i]&v != 0 && v != s.phase[i] {
+				s.remove(i, v, nil)
+				break
+			}
+		}
+	}
+}
+
+// The arguments whose label may be in the given domain
+func (s *search) possible(d uint8) []bool {
+	ub := make([]bool, s.g.n)
+	for i := range ub {
+		ub[i] = s.dom[i]&d != 0
+	}
+	return ub
+}
+
+func (s *search) extension() ArgSet {
+	E := NewArgSet()
+	for i := 0; i < s.g.n; i++ {
+		if s.dom[i] == dIn {
+			E[s.g.args[i]] = true
+		}
+	}
+	return E
+}
+
+func subsetOf(s1, s2 []bool) bool {
+	for i, b := range s1 {
+		if b && !s2[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// The least complete extension. Propagating the constraints of complete
+// labellings, without searching, labels in exactly the arguments of
+// the grounded extension.
+func (g *graph) grounded() ArgSet {
+	s := newSearch(g, completeMode)
+	s.propagate()
+	return s.extension()
+}
+
+// Enumerate the labellings of the given mode, calling visit with the
+// state of the search for each. Stops when visit returns true.
+func (g *graph) labellings(m mode, visit func(*search) bool) {
+	s := newSearch(g, m)
+	s.solve(func() bool {
+		return visit(s)
+	})
+}
+
+// Find a labelling of the given mode in which the argument with
+// index i has a label in the domain d.
+func (g *graph) find(m mode, i int, d uint8) (ArgSet, bool) {
+	s := newSearch(g, m)
+	var E ArgSet
+	if !s.set(i, s.dom[i]&d) {
+		return nil, false
+	}
+	found := s.solve(func() bool {
+		E = s.extension()
+		return true
+	})
+	return E, found
+}
+
+// Extends the d-set M of some labelling of the given mode, i.e. the
+// set of arguments whose label is in the domain d, by searching for
+// labellings with strictly larger d-sets, until no such labelling exists.
+// Returns the maximal d-set found.
+func (g *graph) maximize(m mode, d uint8, M []bool) []bool {
+	// Since the constraints only grow, the nogoods learned are kept.
+	s := newSearch(g, m)
+	for {
+		s.backjump(0)
+		for i, b := range M {
+			if b {
+				s.set(i, s.dom[i]&d)
+			}
+		}
+		s.require(M, d)
+		if !s.solve(func() bool {
+			M = s.possible(d)
+			return true
+		}) || g.cancelled() {
+			return M
+		}
+	}
+}
+
+// Find a labelling of the given mode in which the argument with index i
+// is in and the set of arguments which are in is maximal.
+func (g *graph) findMaximal(m mode, i int) (ArgSet, bool) {
+	s := newSearch(g, m)
+	s.set(i, s.dom[i]&dIn)
+	var M []bool
+	if !s.solve(func() bool {
+		M = s.possible(dIn)
+		return true
+	}) {
+		return nil, false
+	}
+	E := NewArgSet()
+	for j, b := range g.maximize(m, dIn, M) {
+		if b {
+			E[g.args[j]] = true
+		}
+	}
+	return E, true
+}
+
+// Enumerate the labellings of the given mode for which the set of
+// arguments with a label in the domain d is maximal w.r.t. set inclusion,
+// calling visit with the state of the search for each. Stops when visit
+// returns true.
+// With d = in, this enumerates the maximal extensions, e.g. the preferred
+// extensions, given complete labellings. With d = in|out, it
+// enumerates the extensions with maximal range, e.g. the semi-stable
+// extensions, given complete labellings.
+//
+// A labelling is found whose d-set is not a subset of a previously
+// found maximal d-set. It is then extended, by searching for
+// labellings with strictly larger d-sets, until no such labelling
+// exists. Finally all labellings with exactly this maximal d-set
+// are enumerated.
+func (g *graph) maximal(m mode, d uint8, visit func(*search) bool) {
+	// the search for labellings whose d-sets are not subsets of the
+	// maximal d-sets found
+	s := newSearch(g, m)
+	for {
+		s.backjump(0)
+		var M []bool
+		if !s.solve(func() bool {
+			M = s.possible(d)
+			return true
+		}) || g.cancelled() {
+			return
+		}
+		M = g.maximize(m, d, M)
+		if g.cancelled() {
+			return // M may not be maximal
+		}
+		s.backjump(0)
+		s.require(M, d)
+		// enumerate the labellings with exactly this d-set
+		s2 := newSearch(g, m)
+		for i, b := range M {
+			if b {
+				s2.set(i, s2.dom[i]&d)
+			} else {
+				s2.set(i, s2.dom[i]&^d)
+			}
+		}
+		if s2.solve(func() bool {
+			return visit(s2)
+		}) {
+			return
+		}
+	}
+}
+
+// Enumerate the extensions of the AF with the given semantics, calling f
+// for each. Stops when f returns true or done is closed. The search is
+// not cancelled if done is nil.
+func (af *AF) backtrackingEnumerate(done <-chan struct{}, s Semantics, f func(ArgSet) bool) {
+	af.backtrackingSearch(done, s, func(s *search) bool {
+		return f(s.extension())
+	}, f)
+}
+
+// Search for the extensions of the AF with the given semantics. If the
+// extensions are found by searching for labellings, visit is called with
+// the state of the search for each labelling, which corresponds to a
+// single extension. Otherwise f is called for each extension. Stops when
+// visit or f returns true, or done is closed.
+func (af *AF) backtrackingSearch(done <-chan struct{}, s Semantics, visit func(*search) bool, f func(ArgSet) bool) {
+	g := newGraph(af)
+	g.done = done
+	if af.solver == SCCSolver {
+		switch s {
+		case Grounded, Complete, Preferred, Stable:
+			g.decomposedExtensions(s, f)
+			return
+		}
+	}
+	switch s {
+	case Grounded:
+		f(af.GroundedExtension())
+	case Complete:
+		g.labellings(completeMode, visit)
+	case Preferred:
+		g.maximal(completeMode, dIn, visit)
+	case Stable:
+		g.labellings(stableMode, visit)
+	case SemiStable:
+		g.maximal(completeMode, dIn|dOut, visit)
+	case Stage:
+		g.maximal(conflictFreeMode, dIn|dOut, visit)
+	case Ideal:
+		f(af.IdealExtension())
+	case Eager:
+		f(af.EagerExtension())
+	case Naive:
+		g.maximal(conflictFreeMode, dIn, visit)
+	case Admissible:
+		g.labellings(admissibleMode, visit)
+	case CF2, Stage2:
+		base := Naive
+		if s == Stage2 {
+			base = Stage
+		}
+		for _, E := range af.sccRecursive(base) {
+			if f(E) {
+				return
+			}
+		}
+	}
+}
+
+func (af *AF) backtrackingExtensions(s Semantics) []ArgSet {
+	extensions := []ArgSet{}
+	af.backtrackingEnumerate(nil, s, func(E ArgSet) bool {
+		extensions = append(extensions, E)
+		return false
+	})
+	return extensions
+}
+
+func (af *AF) backtrackingCredulousWitness(s Semantics, arg Arg) (ArgSet, bool) {
+	g := newGraph(af)
+	i, found := g.index[arg]
+	if !found || g.external[i] {
+		return nil, false
+	}
+	switch s {
+	case Complete, Admissible:
+		// Every admissible set is a subset of some complete extension.
+		return g.find(completeMode, i, dIn)
+	case Stable:
+		return g.find(stableMode, i, dIn)
+	case Preferred:
+		return g.findMaximal(completeMode, i)
+	case Naive:
+		return g.findMaximal(conflictFreeMode, i)
+	default:
+		var witness ArgSet
+		af.backtrackingEnumerate(nil, s, func(E ArgSet) bool {
+			if E.Contains(arg) {
+				witness = E
+			}
+			return witness != nil
+		})
+		return witness, witness != nil
+	}
+}
+
+func (af *AF) backtrackingSkepticalCounterexample(s Semantics, arg Arg) (ArgSet, bool) {
+	g := newGraph(af)
+	i, found := g.index[arg]
+	if !found || g.external[i] {
+		return af.backtrackingSomeExtension(s)
+	}
+	switch s {
+	case Complete:
+		// The grounded extension is the least complete extension
+		if E := g.grounded(); !E.Contains(arg) {
+			return E, true
+		}
+		return nil, false
+	case Stable:
+		return g.find(stableMode, i, dOut)
+	case Admissible:
+		return NewArgSet(), true
+	case Preferred:
+		if g.grounded().Contains(arg) {
+			return nil, false
+		}
+		if _, ok := g.find(completeMode, i, dIn); !ok {
+			// no preferred extension contains arg
+			return af.backtrackingSomeExtension(Preferred)
+		}
+		fallthrough
+	default:
+		var counterexample ArgSet
+		af.backtrackingEnumerate(nil, s, func(E ArgSet) bool {
+			if !E.Contains(arg) {
+				counterexample = E
+			}
+			return counterexample != nil
+		})
+		return counterexample, counterexample != nil
+	}
+}
+
+func (af *AF) backtrackingCountExtensions(s Semantics) int {
+	n := 0
+	af.backtrackingSearch(nil, s, func(*search) bool {
+		n++
+		return false
+	}, func(ArgSet) bool {
+		n++
+		return false
+	})
+	return n
+}
+
+// Returns the state of a search for a labelling of the given mode whose
+// arguments labelled in are the arguments with the indices i such that
+// M[i] is true, if such a labelling exists, and nil otherwise. Since the
+// labels of the other arguments are determined by M, there is at most one
+// such labelling.
+func (g *graph) labelling(m mode, M []bool) *search {
+	s := newSearch(g, m)
+	for i, b := range M {
+		d := s.dom[i] &^ dIn
+		if b {
+			d = s.dom[i] & dIn
+		}
+		if !s.set(i, d) {
+			return nil
+		}
+	}
+	if !s.solve(func() bool { return true }) {
+		return nil
+	}
+	return s
+}
+
+// Returns true if there is a labelling of the given mode whose d-set, the
+// set of arguments with a label in the domain d, is a strict superset of
+// the d-set M of some labelling of this mode.
+func (g *graph) larger(m mode, d uint8, M []bool) bool {
+	s := newSearch(g, m)
+	for i, b := range M {
+		if b {
+			s.set(i, s.dom[i]&d)
+		}
+	}
+	s.require(M, d)
+	return s.solve(func() bool {
+		return true
+	})
+}
+
+func (af *AF) backtrackingIsExtension(s Semantics, S ArgSet) bool {
+	g := newGraph(af)
+	M := make([]bool, g.n)
+	for arg := range S {
+		i, found := g.index[arg]
+		if !found || i >= g.n {
+			return false // not an argument of the AF
+		}
+		M[i] = true
+	}
+	// S is an extension if it is labelled in by some labelling of the
+	// given mode, which is maximal w.r.t. the arguments with a label in
+	// the domain d, if d is not 0
+	check := func(m mode, d uint8) bool {
+		l := g.labelling(m, M)
+		if l == nil {
+			return false
+		}
+		return d == 0 || !g.larger(m, d, l.possible(d))
+	}
+	switch s {
+	case Complete:
+		return check(completeMode, 0)
+	case Preferred:
+		return check(completeMode, dIn)
+	case Stable:
+		return check(stableMode, 0)
+	case SemiStable:
+		return check(completeMode, dIn|dOut)
+	case Stage:
+		return check(conflictFreeMode, dIn|dOut)
+	case Naive:
+		return check(conflictFreeMode, dIn)
+	case Admissible:
+		return check(admissibleMode, 0)
+	default:
+		isExtension := false
+		af.backtrackingEnumerate(nil, s, func(E ArgSet) bool {
+			isExtension = E.Equals(S)
+			return isExtension
+		})
+		return isExtension
+	}
+}
+
+func (af *AF) backtrackingSomeExtension(s Semantics) (ArgSet, bool) {
+	switch s {
+	case Complete:
+		return newGraph(af).grounded(), true
+	default:
+		var E ArgSet
+		af.backtrackingEnumerate(nil, s, func(S ArgSet) bool {
+			E = S
+			return true
+		})
+		return E, E != nil
+	}
+}
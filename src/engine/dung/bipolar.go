@@ -0,0 +1,311 @@
+// Copyright © 2015 The Carneades Authors
+// This Source Code Form is subject to the terms of the
+// Mozilla Public License, v. 2.0. If a copy of the MPL
+// was not distributed with this file, You can obtain one
+// at http://mozilla.org/MPL/2.0/.
+
+// Bipolar argumentation frameworks, with both attack and support
+// relations between arguments, and their translation into Dung AFs.
+// See: Cayrol, C. and Lagasquie-Schiex, M.-C. Bipolarity in
+// argumentation graphs: Towards a better understanding. International
+// Journal of Approximate Reasoning 54 (2013).
+
+package dung
+
+import "fmt"
+
+// A bipolar argumentation framework. Like the attackers of arguments in
+// AFs, the supporters of each argument are mapped to the argument.
+type BAF struct {
+	args []Arg
+	atks map[Arg][]Arg // arg to attackers
+	sups map[Arg][]Arg // arg to supporters
+}
+
+func NewBAF(args []Arg, atks map[Arg][]Arg, sups map[Arg][]Arg) BAF {
+	return BAF{args: args, atks: atks, sups: sups}
+}
+
+func (baf *BAF) Args() []Arg {
+	return baf.args
+}
+
+func (baf *BAF) Atks() map[Arg][]Arg {
+	return baf.atks
+}
+
+func (baf *BAF) Sups() map[Arg][]Arg {
+	return baf.sups
+}
+
+// The interpretation of the support relation, determining the attacks
+// derived from combinations of attacks and supports.
+type SupportInterpretation int
+
+const (
+	// If a supports b, the acceptance of a implies the acceptance of b
+	DeductiveSupport SupportInterpretation = iota
+	// If a supports b, b cannot be accepted without a
+	NecessarySupport
+	// An argument can only be accepted, and attack other arguments, if
+	// it is supported by a chain of supports starting from an argument
+	// which has no supporters
+	EvidentialSupport
+)
+
+func (i SupportInterpretation) String() string {
+	switch i {
+	case NecessarySupport:
+		return "necessary"
+	case EvidentialSupport:
+		return "evidential"
+	default:
+		return "deductive"
+	}
+}
+
+// Returns, for each argument, the set of arguments it supports directly
+// or via a sequence of supports, including the argument itself.
+func (baf *BAF) supportClosure() map[Arg]ArgSet {
+	supported := make(map[Arg][]Arg) // supporter to supported args
+	for _, b := range baf.args {
+		for _, a := range baf.sups[b] {
+			supported[a] = append(supported[a], b)
+		}
+	}
+	closure := make(map[Arg]ArgSet)
+	for _, a := range baf.args {
+		S := NewArgSet(a)
+		queue := []Arg{a}
+		for len(queue) > 0 {
+			x := queue[0]
+			queue = queue[1:]
+			for _, y := range supported[x] {
+				if !S.Contains(y) {
+					S[y] = true
+					queue = append(queue, y)
+				}
+			}
+		}
+		closure[a] = S
+	}
+	return closure
+}
+
+// Selects, for an argument, the arguments related to it by the
+// support relation, e.g. its direct and indirect supporters.
+type supportSelector func(closure map[Arg]ArgSet, baf *BAF, x Arg) []Arg
+
+// The argument itself
+func self(closure map[Arg]ArgSet, baf *BAF, x Arg) []Arg {
+	return []Arg{x}
+}
+
+// The argument and the arguments supporting it, via a sequence of supports
+func supporters(closure map[Arg]ArgSet, baf *BAF, x Arg) []Arg {
+	args := []Arg{}
+	for _, a := range baf.args {
+		if closure[a].Contains(x) {
+			args = append(args, a)
+		}
+	}
+	return args
+}
+
+// The argument and the arguments it supports, via a sequence of supports
+func supported(closure map[Arg]ArgSet, baf *BAF, x Arg) []Arg {
+	return baf.ordered(closure[x])
+}
+
+// Returns the members of the set in the order of the arguments of the BAF
+func (baf *BAF) ordered(S ArgSet) []Arg {
+	args := []Arg{}
+	for _, arg := range baf.args {
+		if S.Contains(arg) {
+			args = append(args, arg)
+		}
+	}
+	return args
+}
+
+// Derives the attacks by the arguments a on the arguments c such that
+// a is selected by from for some x, x attacks some y and c is selected by
+// to for y. Direct attacks are omitted. The result maps each argument to
+// its derived attackers, without duplicates.
+func (baf *BAF) derive(from, to supportSelector) map[Arg][]Arg {
+	closure := baf.supportClosure()
+	seen := make(map[Arg]ArgSet)
+	for _, arg := range baf.args {
+		seen[arg] = NewArgSet(baf.atks[arg]...)
+	}
+	atks := make(map[Arg][]Arg)
+	for _, y := range baf.args {
+		for _, x := range baf.atks[y] {
+			for _, a := range from(closure, baf, x) {
+				for _, c := range to(closure, baf, y) {
+					if !seen[c].Contains(a) {
+						seen[c][a] = true
+						atks[c] = append(atks[c], a)
+					}
+				}
+			}
+		}
+	}
+	return atks
+}
+
+// Returns the supported attacks of the BAF: a attacks c if a supports
+// some argument, via a sequence of supports, which attacks c.
+// Direct attacks are not included.
+func (baf *BAF) SupportedAttacks() map[Arg][]Arg {
+	return baf.derive(supporters, self)
+}
+
+// Returns the secondary attacks of the BAF: a attacks c if a attacks
+// some argument which supports c, via a sequence of supports.
+// Direct attacks are not included.
+func (baf *BAF) SecondaryAttacks() map[Arg][]Arg {
+	return baf.derive(self, supported)
+}
+
+// Returns the mediated attacks of the BAF: a attacks c if a attacks
+// some argument which is supported by c, via a sequence of supports.
+// Direct attacks are not included.
+func (baf *BAF) MediatedAttacks() map[Arg][]Arg {
+	return baf.derive(self, supporters)
+}
+
+// Returns, for each argument, the minimal evidential supports of the
+// argument: the sets of arguments on a chain of supports from an argument
+// without supporters to the argument, including both, which include no
+// other such set. An argument without supporters is its own evidential
+// support. The number of chains may be exponential in the number of
+// arguments.
+func (baf *BAF) evidentialSupports() map[Arg][]ArgSet {
+	supported := make(map[Arg][]Arg) // supporter to supported args
+	for _, b := range baf.args {
+		for _, a := range baf.sups[b] {
+			supported[a] = append(supported[a], b)
+		}
+	}
+	chains := make(map[Arg][]ArgSet)
+	var extend func(x Arg, S ArgSet)
+	extend = func(x Arg, S ArgSet) {
+		chains[x] = append(chains[x], S.Copy())
+		for _, y := range supported[x] {
+			if !S.Contains(y) {
+				S[y] = true
+				extend(y, S)
+				delete(S, y)
+			}
+		}
+	}
+	for _, a := range baf.args {
+		if len(baf.sups[a]) == 0 {
+			extend(a, NewArgSet(a))
+		}
+	}
+	supports := make(map[Arg][]ArgSet)
+	for _, a := range baf.args {
+		for _, S := range chains[a] {
+			minimal := true
+			for _, T := range chains[a] {
+				if len(T) < len(S) && T.Subset(S) {
+					minimal = false
+					break
+				}
+			}
+			if minimal && !containsArgSet(supports[a], S) {
+				supports[a] = append(supports[a], S)
+			}
+		}
+	}
+	return supports
+}
+
+func containsArgSet(sets []ArgSet, S ArgSet) bool {
+	for _, T := range sets {
+		if T.Equals(S) {
+			return true
+		}
+	}
+	return false
+}
+
+// Translates the BAF into an AF of meta arguments, interpreting support
+// as evidential support: an argument can only be accepted, and attack
+// other arguments, if it is supported by a chain of supports starting from
+// an argument without supporters, and the arguments of the chain are
+// accepted. There is a meta argument for each argument and each of its
+// minimal evidential supports. A meta argument attacks another if its
+// argument attacks some argument of the evidential support of the other,
+// so that attacking a supporter attacks the supported arguments too. The
+// meta argument of an argument with a single minimal evidential support
+// has the id of the argument. The meta arguments of an argument with
+// several minimal evidential supports have the id of the argument
+// followed by "/" and a number, starting with 1. Arguments without
+// evidential support have no meta arguments. An argument is accepted if
+// some meta argument of it is accepted. Returns the AF and a map from the
+// meta arguments to their arguments.
+func (baf *BAF) EvidentialAF() (AF, map[Arg]Arg) {
+	supports := baf.evidentialSupports()
+	type meta struct {
+		arg     Arg
+		support ArgSet
+	}
+	metas := []meta{}
+	args := []Arg{}
+	of := make(map[Arg]Arg)
+	for _, a := range baf.args {
+		for i, S := range supports[a] {
+			m := a
+			if len(supports[a]) > 1 {
+				m = Arg(fmt.Sprintf("%s/%d", a, i+1))
+			}
+			metas = append(metas, meta{a, S})
+			args = append(args, m)
+			of[m] = a
+		}
+	}
+	atks := make(map[Arg][]Arg)
+	for i, m2 := range metas {
+		for j, m1 := range metas {
+			for x := range m2.support {
+				if NewArgSet(baf.atks[x]...).Contains(m1.arg) {
+					atks[args[i]] = append(atks[args[i]], args[j])
+					break
+				}
+			}
+		}
+	}
+	return NewAF(args, atks), of
+}
+
+// Translates the BAF into an AF, given an interpretation of the support
+// relation, so that the semantics of AFs can be applied to BAFs.
+//
+// With deductive support, a attacks c if a supports some x, x attacks
+// some y and c supports y, via sequences of supports. This closes the
+// attack relation under supported and mediated attacks. Dually, with
+// necessary support, a attacks c if some x supports a, x attacks some y
+// and y supports c, closing the attack relation under secondary attacks
+// and attacks by arguments supported by an attacker. With evidential
+// support, the AF of meta arguments of EvidentialAF is returned, whose
+// arguments may differ from the arguments of the BAF.
+func (baf *BAF) AF(i SupportInterpretation) AF {
+	var derived map[Arg][]Arg
+	switch i {
+	case NecessarySupport:
+		derived = baf.derive(supported, supported)
+	case EvidentialSupport:
+		af, _ := baf.EvidentialAF()
+		return af
+	default:
+		derived = baf.derive(supporters, supporters)
+	}
+	atks := make(map[Arg][]Arg)
+	for _, arg := range baf.args {
+		atks[arg] = append(append([]Arg{}, baf.atks[arg]...), derived[arg]...)
+	}
+	return NewAF(baf.args, atks)
+}
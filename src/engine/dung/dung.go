@@ -11,6 +11,7 @@ package dung
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 )
 
@@ -20,10 +21,51 @@ func (arg Arg) String() string {
 	return string(arg)
 }
 
-// Argumentation Framework
+// Argumentation Framework. Attackers which are not arguments of the AF
+// are never in an extension, under every semantics and solver, but are
+// out if some argument attacking them is in.
 type AF struct {
-	args []Arg         // the arguments
-	atks map[Arg][]Arg // arguments attacking each key argument
+	args   []Arg         // the arguments
+	atks   map[Arg][]Arg // arguments attacking each key argument
+	solver Solver        // the algorithm used to compute extensions
+}
+
+// A Solver selects the algorithm used to compute the extensions of an AF.
+type Solver int
+
+const (
+	// Labelling-based backtracking search, with constraint propagation.
+	// This is the default.
+	BacktrackingSolver Solver = iota
+	// Enumeration of all subsets of the arguments. Exponential in the
+	// number of arguments, but simple, and thus kept as a reference
+	// implementation for testing the other solvers.
+	SubsetSolver
+	// Backtracking search, applied to one strongly connected component
+	// at a time, along the topological order of the components. Used for
+	// grounded, complete, preferred and stable semantics. Other semantics
+	// are computed as by the BacktrackingSolver.
+	SCCSolver
+)
+
+func (s Solver) String() string {
+	switch s {
+	case SubsetSolver:
+		return "subsets"
+	case SCCSolver:
+		return "scc"
+	default:
+		return "backtracking"
+	}
+}
+
+// Select the solver used to compute the extensions of the AF.
+func (af *AF) SetSolver(s Solver) {
+	af.solver = s
+}
+
+func (af *AF) Solver() Solver {
+	return af.solver
 }
 
 func (af *AF) Args() []Arg {
@@ -35,7 +77,66 @@ func (af *AF) Atks() map[Arg][]Arg {
 }
 
 func NewAF(args []Arg, atks map[Arg][]Arg) AF {
-	return AF{args, atks}
+	return AF{args: args, atks: atks}
+}
+
+// The following methods modify the AF destructively. Since AFs share
+// their argument slice and attack map with the arguments of NewAF and
+// with copies of the AF, these should not be modified concurrently
+// or used afterwards.
+
+// Adds an argument to the AF, unless it is already an argument of the AF.
+func (af *AF) AddArg(arg Arg) {
+	for _, a := range af.args {
+		if a == arg {
+			return
+		}
+	}
+	af.args = append(af.args, arg)
+}
+
+// Removes an argument from the AF, together with the attacks on and by
+// the argument.
+func (af *AF) RemoveArg(arg Arg) {
+	for i, a := range af.args {
+		if a == arg {
+			af.args = append(af.args[:i:i], af.args[i+1:]...)
+			break
+		}
+	}
+	delete(af.atks, arg)
+	for target := range af.atks {
+		af.RemoveAttack(arg, target)
+	}
+}
+
+// Adds an attack on the argument to, unless the attack already exists.
+// The attacker need not be an argument of the AF. Attackers which are not
+// arguments of the AF are never in any extension.
+func (af *AF) AddAttack(from, to Arg) {
+	for _, atk := range af.atks[to] {
+		if atk == from {
+			return
+		}
+	}
+	if af.atks == nil {
+		af.atks = make(map[Arg][]Arg)
+	}
+	af.atks[to] = append(af.atks[to], from)
+}
+
+// Removes an attack, if it exists.
+func (af *AF) RemoveAttack(from, to Arg) {
+	atks := af.atks[to]
+	for i, atk := range atks {
+		if atk == from {
+			af.atks[to] = append(atks[:i:i], atks[i+1:]...)
+			if len(af.atks[to]) == 0 {
+				delete(af.atks, to)
+			}
+			return
+		}
+	}
 }
 
 func (af *AF) String() string {
@@ -213,15 +314,47 @@ func (l Labelling) Get(arg Arg) Label {
 }
 
 func (l Labelling) AsExtension() ArgSet {
+	return l.Labelled(In)
+}
+
+// Returns the set of arguments with the given label
+func (l Labelling) Labelled(label Label) ArgSet {
 	S := make(map[Arg]bool)
-	for arg, label := range l {
-		if label == In {
+	for arg, v := range l {
+		if v == label {
 			S[arg] = true
 		}
 	}
 	return ArgSet(S)
 }
 
+func (l1 Labelling) Equals(l2 Labelling) bool {
+	if len(l1) != len(l2) {
+		return false
+	}
+	for arg, label := range l1 {
+		if v, found := l2[arg]; !found || v != label {
+			return false
+		}
+	}
+	return true
+}
+
+// Prints the arguments with each label, sorted, e.g.
+// {in: [a,c], out: [b], undecided: []}
+func (l Labelling) String() string {
+	s := []string{}
+	for _, label := range []Label{In, Out, Undecided} {
+		args := []string{}
+		for arg := range l.Labelled(label) {
+			args = append(args, string(arg))
+		}
+		sort.Strings(args)
+		s = append(s, fmt.Sprintf("%s: [%s]", label, strings.Join(args, ",")))
+	}
+	return fmt.Sprintf("{%s}", strings.Join(s, ", "))
+}
+
 type Semantics int
 
 const (
@@ -229,10 +362,92 @@ const (
 	Complete
 	Preferred
 	Stable
+	SemiStable
+	Stage
+	Ideal
+	Eager
+	Naive
+	Admissible
+	CF2
+	Stage2
 )
 
+func (s Semantics) String() string {
+	switch s {
+	case Grounded:
+		return "grounded"
+	case Complete:
+		return "complete"
+	case Preferred:
+		return "preferred"
+	case Stable:
+		return "stable"
+	case SemiStable:
+		return "semi-stable"
+	case Stage:
+		return "stage"
+	case Ideal:
+		return "ideal"
+	case Eager:
+		return "eager"
+	case Naive:
+		return "naive"
+	case Admissible:
+		return "admissible"
+	case CF2:
+		return "cf2"
+	case Stage2:
+		return "stage2"
+	default:
+		return "unknown"
+	}
+}
+
+// The ICCMA abbreviations of the semantics, e.g. GR for grounded
+var semanticsAbbreviations = map[string]Semantics{
+	"GR":   Grounded,
+	"CO":   Complete,
+	"PR":   Preferred,
+	"ST":   Stable,
+	"SST":  SemiStable,
+	"STG":  Stage,
+	"ID":   Ideal,
+	"EG":   Eager,
+	"NA":   Naive,
+	"AD":   Admissible,
+	"CF2":  CF2,
+	"STG2": Stage2,
+}
+
+// Returns the semantics with the given ICCMA abbreviation, e.g. GR for
+// grounded, and false if the abbreviation is unknown
+func ParseSemantics(abbreviation string) (Semantics, bool) {
+	s, ok := semanticsAbbreviations[abbreviation]
+	return s, ok
+}
+
 func (af *AF) GroundedExtension() ArgSet {
+	return af.GroundedLabelling().AsExtension()
+}
+
+// Returns the grounded labelling of the AF, labelling every argument of
+// the AF in, out or undecided.
+func (af *AF) GroundedLabelling() Labelling {
 	l := NewLabelling()
+	declared := NewArgSet(af.args...)
+	// Attackers which are not arguments of the AF are never in, and
+	// out if some argument attacking them is in.
+	label := func(atk Arg) Label {
+		if declared.Contains(atk) {
+			return l.Get(atk)
+		}
+		for _, b := range af.atks[atk] {
+			if declared.Contains(b) && l.Get(b) == In {
+				return Out
+			}
+		}
+		return Undecided
+	}
 	var changed bool
 	for {
 		changed = false
@@ -246,7 +461,7 @@ func (af *AF) GroundedExtension() ArgSet {
 			atks := af.atks[arg]
 			allOut := true // assumption
 			for _, atk := range atks {
-				switch l.Get(atk) {
+				switch label(atk) {
 				case In:
 					allOut = false
 					l[arg] = Out // since an attacker is in
@@ -263,29 +478,38 @@ func (af *AF) GroundedExtension() ArgSet {
 			}
 		}
 		if changed == false {
-			return l.AsExtension()
+			break
+		}
+	}
+	// Arguments which are neither in nor out are undecided
+	for _, arg := range af.args {
+		if _, found := l[arg]; !found {
+			l[arg] = Undecided
 		}
 	}
+	return l
 }
 
 // Traverse subsets of the args of an AF, starting with the empty set.
 // Visit each subset exactly once.
 func (af *AF) Traverse(f func(L ArgSet)) {
-	allOut := NewArgSet()
-	// count := 1
+	af.TraverseUntil(func(L ArgSet) bool {
+		f(L)
+		return false
+	})
+}
 
-	var subsets func(int, ArgSet)
-	subsets = func(i int, L ArgSet) {
+// Traverse subsets of the args of an AF, as Traverse does, until f
+// returns true. Returns true if the traversal was stopped by f.
+func (af *AF) TraverseUntil(f func(L ArgSet) bool) bool {
+	var subsets func(int, ArgSet) bool
+	subsets = func(i int, L ArgSet) bool {
 		if i == len(af.args) {
-			// fmt.Printf("%v. ", count)
-			// count++
-			f(L)
-			return
+			return f(L)
 		}
-		subsets(i+1, L)
-		subsets(i+1, L.Add(af.args[i]))
+		return subsets(i+1, L) || subsets(i+1, L.Add(af.args[i]))
 	}
-	subsets(0, allOut)
+	return subsets(0, NewArgSet())
 }
 
 // Find the first subset of the args of an AF, starting with the empty set,
@@ -374,7 +598,372 @@ func (af *AF) stable(E ArgSet) bool {
 	return true
 }
 
+// Returns the extensions of the AF with the given semantics.
+func (af *AF) Extensions(s Semantics) []ArgSet {
+	if af.solver == SubsetSolver {
+		return af.subsetExtensions(s)
+	}
+	return af.backtrackingExtensions(s)
+}
+
+// Returns the labelling of the arguments of the AF corresponding to an
+// extension E: the members of E are in, the arguments attacked by some
+// member of E are out and the remaining arguments are undecided. For
+// complete extensions, this is the complete labelling of the extension.
+func (af *AF) ExtensionLabelling(E ArgSet) Labelling {
+	l := NewLabelling()
+	for _, arg := range af.args {
+		switch {
+		case E.Contains(arg):
+			l[arg] = In
+		case af.attackedBy(E, arg):
+			l[arg] = Out
+		default:
+			l[arg] = Undecided
+		}
+	}
+	return l
+}
+
+// Is arg attacked by some member of E?
+func (af *AF) attackedBy(E ArgSet, arg Arg) bool {
+	for _, atk := range af.atks[arg] {
+		if E.Contains(atk) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the labellings of the extensions of the AF with the given
+// semantics. See ExtensionLabelling.
+func (af *AF) Labellings(s Semantics) []Labelling {
+	if s == Grounded {
+		return []Labelling{af.GroundedLabelling()}
+	}
+	labellings := []Labelling{}
+	for _, E := range af.Extensions(s) {
+		labellings = append(labellings, af.ExtensionLabelling(E))
+	}
+	return labellings
+}
+
+// Returns the labelling of some extension of the AF with the given
+// semantics, if one exists. See SomeExtension.
+func (af *AF) SomeLabelling(s Semantics) (Labelling, bool) {
+	E, ok := af.SomeExtension(s)
+	if !ok {
+		return nil, false
+	}
+	return af.ExtensionLabelling(E), true
+}
+
 func (af *AF) CompleteExtensions() []ArgSet {
+	return af.Extensions(Complete)
+}
+
+func (af *AF) PreferredExtensions() []ArgSet {
+	return af.Extensions(Preferred)
+}
+
+func (af *AF) StableExtensions() []ArgSet {
+	return af.Extensions(Stable)
+}
+
+// The semi-stable extensions are the complete extensions E for which
+// E ∪ E+, the range of E, is maximal w.r.t. set inclusion, where
+// E+ is the set of arguments attacked by E.
+func (af *AF) SemiStableExtensions() []ArgSet {
+	return af.Extensions(SemiStable)
+}
+
+// The stage extensions are the conflict-free sets of arguments with
+// maximal range.
+func (af *AF) StageExtensions() []ArgSet {
+	return af.Extensions(Stage)
+}
+
+// The naive extensions are the maximal conflict-free sets of arguments.
+func (af *AF) NaiveExtensions() []ArgSet {
+	return af.Extensions(Naive)
+}
+
+// The admissible sets are the conflict-free sets of arguments which
+// defend all their members.
+func (af *AF) AdmissibleExtensions() []ArgSet {
+	return af.Extensions(Admissible)
+}
+
+// The CF2 extensions are computed SCC-recursively, using naive semantics
+// for single SCCs. See the SCCs method.
+func (af *AF) CF2Extensions() []ArgSet {
+	return af.Extensions(CF2)
+}
+
+// The stage2 extensions are computed SCC-recursively, using stage semantics
+// for single SCCs.
+func (af *AF) Stage2Extensions() []ArgSet {
+	return af.Extensions(Stage2)
+}
+
+// The ideal extension is the largest admissible set which is a subset
+// of every preferred extension.
+func (af *AF) IdealExtension() ArgSet {
+	return af.largestAdmissibleSubset(intersection(af.PreferredExtensions()))
+}
+
+// The eager extension is the largest admissible set which is a subset
+// of every semi-stable extension.
+func (af *AF) EagerExtension() ArgSet {
+	return af.largestAdmissibleSubset(intersection(af.SemiStableExtensions()))
+}
+
+func intersection(extensions []ArgSet) ArgSet {
+	if len(extensions) == 0 {
+		return NewArgSet()
+	}
+	S := extensions[0].Copy()
+	for _, E := range extensions[1:] {
+		for arg := range S {
+			if !E.Contains(arg) {
+				delete(S, arg)
+			}
+		}
+	}
+	return S
+}
+
+// Returns the largest admissible subset of a conflict-free set of
+// arguments, by repeatedly removing the members which are not
+// defended by the remaining members.
+func (af *AF) largestAdmissibleSubset(S ArgSet) ArgSet {
+	S = S.Copy()
+	for {
+		changed := false
+		for arg := range S {
+			if !af.defends(S, arg) {
+				delete(S, arg)
+				changed = true
+			}
+		}
+		if !changed {
+			return S
+		}
+	}
+}
+
+// Does L defend arg, by attacking every attacker of arg?
+func (af *AF) defends(L ArgSet, arg Arg) bool {
+	for _, atk := range af.atks[arg] {
+		defended := false
+		for _, defender := range af.atks[atk] {
+			if L.Contains(defender) {
+				defended = true
+				break
+			}
+		}
+		if !defended {
+			return false
+		}
+	}
+	return true
+}
+
+func (af *AF) CredulouslyInferred(s Semantics, arg Arg) bool {
+	if af.solver == SubsetSolver {
+		return af.subsetCredulouslyInferred(s, arg)
+	}
+	_, ok := af.backtrackingCredulousWitness(s, arg)
+	return ok
+}
+
+func (af *AF) SkepticallyInferred(s Semantics, arg Arg) bool {
+	if af.solver == SubsetSolver {
+		return af.subsetSkepticallyInferred(s, arg)
+	}
+	_, ok := af.backtrackingSkepticalCounterexample(s, arg)
+	return !ok
+}
+
+// Returns an extension with the given semantics which contains the
+// argument, if one exists, as a witness of its credulous acceptance.
+// The boolean value returned is false if the argument is not credulously
+// inferred.
+func (af *AF) CredulousWitness(s Semantics, arg Arg) (ArgSet, bool) {
+	if af.solver == SubsetSolver {
+		for _, E := range af.subsetExtensions(s) {
+			if E.Contains(arg) {
+				return E, true
+			}
+		}
+		return nil, false
+	}
+	return af.backtrackingCredulousWitness(s, arg)
+}
+
+// Returns an extension with the given semantics which does not contain
+// the argument, if one exists, as a counterexample to its skeptical
+// acceptance. The boolean value returned is false if the argument is
+// skeptically inferred.
+func (af *AF) SkepticalCounterexample(s Semantics, arg Arg) (ArgSet, bool) {
+	if af.solver == SubsetSolver {
+		for _, E := range af.subsetExtensions(s) {
+			if !E.Contains(arg) {
+				return E, true
+			}
+		}
+		return nil, false
+	}
+	return af.backtrackingSkepticalCounterexample(s, arg)
+}
+
+// Returns an extension of the AF with the given semantics, if one exists.
+// The boolean value returned is false if no extension exists for the chosen
+// semantics.
+func (af *AF) SomeExtension(s Semantics) (ArgSet, bool) {
+	if af.solver == SubsetSolver {
+		return af.subsetSomeExtension(s)
+	}
+	return af.backtrackingSomeExtension(s)
+}
+
+// Returns the number of extensions of the AF with the given semantics.
+// The backtracking solvers count the extensions as they are found,
+// without collecting them.
+func (af *AF) CountExtensions(s Semantics) int {
+	if af.solver == SubsetSolver {
+		return len(af.subsetExtensions(s))
+	}
+	return af.backtrackingCountExtensions(s)
+}
+
+// Returns true if S is an extension of the AF with the given semantics.
+// The backtracking solvers check whether S is an extension without
+// enumerating extensions, except for the grounded, ideal, eager, CF2 and
+// stage2 semantics.
+func (af *AF) IsExtension(s Semantics, S ArgSet) bool {
+	if af.solver == SubsetSolver {
+		for _, E := range af.subsetExtensions(s) {
+			if E.Equals(S) {
+				return true
+			}
+		}
+		return false
+	}
+	return af.backtrackingIsExtension(s, S)
+}
+
+// The reference solver, which enumerates all subsets of the arguments.
+
+func (af *AF) subsetExtensions(s Semantics) []ArgSet {
+	switch s {
+	case Grounded:
+		return []ArgSet{af.GroundedExtension()}
+	case Complete:
+		return af.subsetCompleteExtensions()
+	case Preferred:
+		return af.subsetPreferredExtensions()
+	case Stable:
+		return af.subsetStableExtensions()
+	case SemiStable:
+		return maximal(af.subsetCompleteExtensions(), af.rangeOf)
+	case Stage:
+		return maximal(af.subsetConflictFreeSets(), af.rangeOf)
+	case Ideal:
+		return []ArgSet{af.IdealExtension()}
+	case Eager:
+		return []ArgSet{af.EagerExtension()}
+	case Naive:
+		return maximal(af.subsetConflictFreeSets(), func(E ArgSet) ArgSet {
+			return E
+		})
+	case Admissible:
+		extensions := []ArgSet{}
+		af.Traverse(func(A ArgSet) {
+			if af.conflictFree(A) && af.admissible(A) {
+				extensions = append(extensions, A)
+			}
+		})
+		return extensions
+	case CF2:
+		return af.sccRecursive(Naive)
+	case Stage2:
+		return af.sccRecursive(Stage)
+	default:
+		return []ArgSet{}
+	}
+}
+
+func (af *AF) conflictFree(L ArgSet) bool {
+	for arg, _ := range L {
+		for _, atk := range af.atks[arg] {
+			if L.Contains(atk) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// A conflict-free set is admissible if it defends all its members
+func (af *AF) admissible(L ArgSet) bool {
+	for arg, _ := range L {
+		if !af.defends(L, arg) {
+			return false
+		}
+	}
+	return true
+}
+
+// The range of a set of arguments E is E ∪ E+, where E+ is the
+// set of arguments attacked by E.
+func (af *AF) rangeOf(E ArgSet) ArgSet {
+	R := E.Copy()
+	for _, arg := range af.args {
+		for _, atk := range af.atks[arg] {
+			if E.Contains(atk) {
+				R[arg] = true
+				break
+			}
+		}
+	}
+	return R
+}
+
+// Returns the sets in the given list whose measure is maximal w.r.t.
+// set inclusion.
+func maximal(sets []ArgSet, measure func(ArgSet) ArgSet) []ArgSet {
+	measures := []ArgSet{}
+	for _, S := range sets {
+		measures = append(measures, measure(S))
+	}
+	result := []ArgSet{}
+	for i, S := range sets {
+		isMaximal := true
+		for j := range sets {
+			if measures[i].Subset(measures[j]) && !measures[i].Equals(measures[j]) {
+				isMaximal = false
+				break
+			}
+		}
+		if isMaximal {
+			result = append(result, S)
+		}
+	}
+	return result
+}
+
+func (af *AF) subsetConflictFreeSets() []ArgSet {
+	sets := []ArgSet{}
+	af.Traverse(func(A ArgSet) {
+		if af.conflictFree(A) {
+			sets = append(sets, A)
+		}
+	})
+	return sets
+}
+
+func (af *AF) subsetCompleteExtensions() []ArgSet {
 	extensions := []ArgSet{}
 	af.Traverse(func(A ArgSet) {
 		if af.complete(A) {
@@ -384,7 +973,7 @@ func (af *AF) CompleteExtensions() []ArgSet {
 	return extensions
 }
 
-func (af *AF) PreferredExtensions() []ArgSet {
+func (af *AF) subsetPreferredExtensions() []ArgSet {
 	candidates := []ArgSet{}
 
 	subsetOfCandidate := func(L1 ArgSet) bool {
@@ -396,7 +985,7 @@ func (af *AF) PreferredExtensions() []ArgSet {
 		return false
 	}
 
-	for _, CE := range af.CompleteExtensions() {
+	for _, CE := range af.subsetCompleteExtensions() {
 		if subsetOfCandidate(CE) {
 			continue
 		}
@@ -413,9 +1002,9 @@ func (af *AF) PreferredExtensions() []ArgSet {
 	return candidates
 }
 
-func (af *AF) StableExtensions() []ArgSet {
+func (af *AF) subsetStableExtensions() []ArgSet {
 	extensions := []ArgSet{}
-	for _, CE := range af.CompleteExtensions() {
+	for _, CE := range af.subsetCompleteExtensions() {
 		if af.stable(CE) {
 			extensions = append(extensions, CE)
 		}
@@ -423,7 +1012,7 @@ func (af *AF) StableExtensions() []ArgSet {
 	return extensions
 }
 
-func (af *AF) CredulouslyInferred(s Semantics, arg Arg) bool {
+func (af *AF) subsetCredulouslyInferred(s Semantics, arg Arg) bool {
 	switch s {
 	case Grounded:
 		return af.GroundedExtension().Contains(arg)
@@ -437,7 +1026,7 @@ func (af *AF) CredulouslyInferred(s Semantics, arg Arg) bool {
 			return false
 		}
 	case Preferred:
-		s := af.PreferredExtensions()
+		s := af.subsetPreferredExtensions()
 		for _, E := range s {
 			if E.Contains(arg) {
 				return true
@@ -454,11 +1043,16 @@ func (af *AF) CredulouslyInferred(s Semantics, arg Arg) bool {
 			return false
 		}
 	default:
+		for _, E := range af.subsetExtensions(s) {
+			if E.Contains(arg) {
+				return true
+			}
+		}
 		return false
 	}
 }
 
-func (af *AF) SkepticallyInferred(s Semantics, arg Arg) bool {
+func (af *AF) subsetSkepticallyInferred(s Semantics, arg Arg) bool {
 	memberOfAll := func(extensions []ArgSet) bool {
 		for _, E := range extensions {
 			if !E.Contains(arg) {
@@ -471,27 +1065,24 @@ func (af *AF) SkepticallyInferred(s Semantics, arg Arg) bool {
 	case Grounded:
 		return af.GroundedExtension().Contains(arg)
 	case Complete:
-		return memberOfAll(af.CompleteExtensions())
+		return memberOfAll(af.subsetCompleteExtensions())
 	case Preferred:
-		return memberOfAll(af.PreferredExtensions())
+		return memberOfAll(af.subsetPreferredExtensions())
 	case Stable:
-		return memberOfAll(af.StableExtensions())
+		return memberOfAll(af.subsetStableExtensions())
 	default:
-		return false
+		return memberOfAll(af.subsetExtensions(s))
 	}
 }
 
-// Returns an extension of the AF with the given semantics, if one exists.
-// The boolean value returned is false if no extension exists for the chosen
-// semantics.
-func (af *AF) SomeExtension(s Semantics) (ArgSet, bool) {
+func (af *AF) subsetSomeExtension(s Semantics) (ArgSet, bool) {
 	switch s {
 	case Grounded:
 		return af.GroundedExtension(), true
 	case Complete:
 		return af.Find(af.complete)
 	case Preferred:
-		extensions := af.PreferredExtensions()
+		extensions := af.subsetPreferredExtensions()
 		if len(extensions) > 0 {
 			return extensions[0], true
 		} else {
@@ -502,6 +1093,11 @@ func (af *AF) SomeExtension(s Semantics) (ArgSet, bool) {
 			return af.complete(E) && af.stable(E)
 		})
 	default:
-		return nil, false
+		extensions := af.subsetExtensions(s)
+		if len(extensions) > 0 {
+			return extensions[0], true
+		} else {
+			return nil, false
+		}
 	}
 }
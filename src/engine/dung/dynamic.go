@@ -0,0 +1,389 @@
+// Copyright © 2015 The Carneades Authors
+// This Source Code Form is subject to the terms of the
+// Mozilla Public License, v. 2.0. If a copy of the MPL
+// was not distributed with this file, You can obtain one
+// at http://mozilla.org/MPL/2.0/.
+
+// Incremental evaluation of AFs which are modified one argument or
+// attack at a time.
+
+package dung
+
+// An Evaluator maintains the grounded labelling of an AF, and its
+// preferred and stable extensions once these have been requested, while
+// the AF is modified using the methods of the evaluator.
+//
+// After a modification, only the arguments affected by the modification,
+// i.e. the arguments reachable via attacks from the argument whose
+// attackers changed, are reevaluated. The remaining arguments form an
+// unattacked set. Since the grounded, complete and preferred semantics
+// satisfy directionality, the labels of these arguments do not change, and
+// the extensions restricted to them are the preferred extensions of the
+// unchanged part of the AF. The stable extensions of the unchanged part
+// are its preferred extensions attacking all of its other arguments.
+// Each of these is extended to the affected arguments, by computing the
+// extensions of a framework of the affected arguments, in which the
+// attacks of arguments which are in are represented by an unattacked
+// argument and the attacks of undecided arguments by an undecided
+// argument.
+type Evaluator struct {
+	af        *AF
+	targets   map[Arg][]Arg // arg to the args it attacks
+	grounded  Labelling
+	preferred []ArgSet // nil if not requested
+	stable    []ArgSet // nil if not requested
+}
+
+func NewEvaluator(af *AF) *Evaluator {
+	e := &Evaluator{af: af, targets: make(map[Arg][]Arg)}
+	// attacks on undeclared arguments are indexed too, since the
+	// arguments may be added later
+	for arg, atks := range af.atks {
+		for _, atk := range atks {
+			e.targets[atk] = append(e.targets[atk], arg)
+		}
+	}
+	e.grounded = NewLabelling()
+	e.updateGrounded(NewArgSet(af.args...))
+	return e
+}
+
+func (e *Evaluator) AF() *AF {
+	return e.af
+}
+
+func (e *Evaluator) GroundedLabelling() Labelling {
+	return e.grounded
+}
+
+func (e *Evaluator) GroundedExtension() ArgSet {
+	return e.grounded.AsExtension()
+}
+
+func (e *Evaluator) PreferredExtensions() []ArgSet {
+	if e.preferred == nil {
+		e.preferred = e.af.PreferredExtensions()
+	}
+	return e.preferred
+}
+
+func (e *Evaluator) StableExtensions() []ArgSet {
+	if e.stable == nil {
+		e.PreferredExtensions()
+		e.stable = e.af.StableExtensions()
+	}
+	return e.stable
+}
+
+// Returns the arguments of the AF reachable from arg via attacks,
+// including arg, if it is an argument of the AF.
+func (e *Evaluator) affected(arg Arg) ArgSet {
+	args := NewArgSet(e.af.args...)
+	D := NewArgSet()
+	queue := []Arg{arg}
+	visited := NewArgSet(arg)
+	for len(queue) > 0 {
+		a := queue[0]
+		queue = queue[1:]
+		if args.Contains(a) {
+			D[a] = true
+		}
+		for _, b := range e.targets[a] {
+			if !visited.Contains(b) {
+				visited[b] = true
+				queue = append(queue, b)
+			}
+		}
+	}
+	return D
+}
+
+func (e *Evaluator) AddArg(arg Arg) {
+	for _, a := range e.af.args {
+		if a == arg {
+			return
+		}
+	}
+	D := e.affected(arg) // attacks by arg may already exist
+	e.af.AddArg(arg)
+	D[arg] = true
+	e.update(D)
+}
+
+func (e *Evaluator) RemoveArg(arg Arg) {
+	D := e.affected(arg)
+	e.af.RemoveArg(arg)
+	for atk, targets := range e.targets {
+		for _, b := range targets {
+			if b == arg {
+				e.removeTarget(atk, arg)
+				break
+			}
+		}
+	}
+	delete(e.targets, arg)
+	delete(e.grounded, arg)
+	delete(D, arg)
+	e.update(D)
+}
+
+func (e *Evaluator) AddAttack(from, to Arg) {
+	for _, atk := range e.af.atks[to] {
+		if atk == from {
+			return
+		}
+	}
+	e.af.AddAttack(from, to)
+	e.targets[from] = append(e.targets[from], to)
+	e.update(e.affected(to))
+}
+
+func (e *Evaluator) RemoveAttack(from, to Arg) {
+	D := e.affected(to)
+	e.af.RemoveAttack(from, to)
+	e.removeTarget(from, to)
+	e.update(D)
+}
+
+func (e *Evaluator) removeTarget(from, to Arg) {
+	targets := e.targets[from]
+	for i, b := range targets {
+		if b == to {
+			e.targets[from] = append(targets[:i:i], targets[i+1:]...)
+			return
+		}
+	}
+}
+
+// Updates the labelling and the cached extensions after the arguments
+// in D have been affected by a modification of the AF
+func (e *Evaluator) update(D ArgSet) {
+	e.updateGrounded(D)
+	if e.preferred != nil {
+		e.updateExtensions(D)
+	}
+}
+
+// Computes the grounded labels of the arguments in D, given the labels
+// of the other arguments, as in GroundedExtension
+func (e *Evaluator) updateGrounded(D ArgSet) {
+	args := []Arg{}
+	for _, arg := range e.af.args {
+		if D.Contains(arg) {
+			delete(e.grounded, arg)
+			args = append(args, arg)
+		}
+	}
+	l := e.grounded
+	declared := NewArgSet(e.af.args...)
+	// Attackers which are not arguments of the AF are never in, and
+	// out if some argument attacking them is in.
+	label := func(atk Arg) Label {
+		if declared.Contains(atk) {
+			return l.Get(atk)
+		}
+		for _, b := range e.af.atks[atk] {
+			if declared.Contains(b) && l[b] == In {
+				return Out
+			}
+		}
+		return Undecided
+	}
+	for {
+		changed := false
+		for _, arg := range args {
+			if _, found := l[arg]; found {
+				continue
+			}
+			allOut := true
+			for _, atk := range e.af.atks[arg] {
+				switch label(atk) {
+				case In:
+					allOut = false
+					l[arg] = Out
+					changed = true
+				case Undecided:
+					allOut = false
+				}
+			}
+			if allOut {
+				l[arg] = In
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+	// Arguments which are neither in nor out are undecided
+	for _, arg := range args {
+		if _, found := l[arg]; !found {
+			l[arg] = Undecided
+		}
+	}
+}
+
+// The names of the arguments representing attacks by arguments outside
+// of the affected arguments which are in and undecided, respectively.
+// Other names are used if these are names of arguments of the AF or
+// of their attackers.
+func (e *Evaluator) syntheticArgs() (Arg, Arg) {
+	args := NewArgSet(e.af.args...)
+	for atk := range e.targets {
+		args[atk] = true
+	}
+	inArg, undecArg := Arg("in"), Arg("undecided")
+	for args.Contains(inArg) || args.Contains(undecArg) {
+		inArg, undecArg = "_"+inArg, "_"+undecArg
+	}
+	return inArg, undecArg
+}
+
+// Returns the AF of the arguments in D, given the extension E of the
+// remaining arguments. Attackers which are not arguments of the AF are
+// kept, with their attackers, since whether they are out may depend on
+// the arguments in D.
+func (e *Evaluator) local(D ArgSet, E ArgSet, inArg, undecArg Arg) AF {
+	args := []Arg{}
+	for _, arg := range e.af.args {
+		if D.Contains(arg) {
+			args = append(args, arg)
+		}
+	}
+	attacked := func(b Arg) bool {
+		for _, atk := range e.af.atks[b] {
+			if E.Contains(atk) {
+				return true
+			}
+		}
+		return false
+	}
+	declared := NewArgSet(e.af.args...)
+	atks := make(map[Arg][]Arg)
+	in := false
+	queue := append([]Arg{}, args...)
+	queued := NewArgSet(args...)
+	for len(queue) > 0 {
+		arg := queue[0]
+		queue = queue[1:]
+		seen := NewArgSet()
+		for _, atk := range e.af.atks[arg] {
+			switch {
+			case D.Contains(atk):
+				atks[arg] = append(atks[arg], atk)
+			case !declared.Contains(atk):
+				atks[arg] = append(atks[arg], atk)
+				if !queued.Contains(atk) {
+					queued[atk] = true
+					queue = append(queue, atk)
+				}
+			case E.Contains(atk):
+				if !seen.Contains(inArg) {
+					seen[inArg] = true
+					in = true
+					atks[arg] = append(atks[arg], inArg)
+				}
+			case attacked(atk):
+				continue // the attacker is out
+			default:
+				// the attacker is undecided
+				if !seen.Contains(undecArg) {
+					seen[undecArg] = true
+					atks[arg] = append(atks[arg], undecArg)
+				}
+			}
+		}
+	}
+	if in {
+		args = append(args, inArg)
+	}
+	af := NewAF(args, atks)
+	af.SetSolver(e.af.solver)
+	return af
+}
+
+// Updates the cached preferred and stable extensions after the arguments
+// in D have been affected by a modification of the AF
+func (e *Evaluator) updateExtensions(D ArgSet) {
+	inArg, undecArg := e.syntheticArgs()
+	U := NewArgSet()
+	for _, arg := range e.af.args {
+		if !D.Contains(arg) {
+			U[arg] = true
+		}
+	}
+	// the preferred extensions of the unaffected arguments
+	projections := []ArgSet{}
+	for _, E := range e.preferred {
+		P := NewArgSet()
+		for arg := range E {
+			if U.Contains(arg) {
+				P[arg] = true
+			}
+		}
+		if !containsSet(projections, P) {
+			projections = append(projections, P)
+		}
+	}
+	// extends E with the extensions of the affected arguments
+	extend := func(E ArgSet, l []ArgSet) []ArgSet {
+		result := []ArgSet{}
+		for _, E2 := range l {
+			E3 := E.Copy()
+			for arg := range E2 {
+				if arg != inArg {
+					E3[arg] = true
+				}
+			}
+			result = append(result, E3)
+		}
+		return result
+	}
+	preferred := []ArgSet{}
+	for _, P := range projections {
+		af := e.local(D, P, inArg, undecArg)
+		preferred = append(preferred, extend(P, af.PreferredExtensions())...)
+	}
+	e.preferred = preferred
+	if e.stable == nil {
+		return
+	}
+	stable := []ArgSet{}
+	for _, P := range projections {
+		if !e.attacksAll(P, U) {
+			continue
+		}
+		af := e.local(D, P, inArg, undecArg)
+		stable = append(stable, extend(P, af.StableExtensions())...)
+	}
+	e.stable = stable
+}
+
+// Returns true if E attacks every member of U which is not in E
+func (e *Evaluator) attacksAll(E ArgSet, U ArgSet) bool {
+	for arg := range U {
+		if E.Contains(arg) {
+			continue
+		}
+		attacked := false
+		for _, atk := range e.af.atks[arg] {
+			if E.Contains(atk) {
+				attacked = true
+				break
+			}
+		}
+		if !attacked {
+			return false
+		}
+	}
+	return true
+}
+
+func containsSet(l []ArgSet, S ArgSet) bool {
+	for _, S2 := range l {
+		if S2.Equals(S) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,447 @@
+// Copyright © 2015 The Carneades Authors
+// This Source Code Form is subject to the terms of the
+// Mozilla Public License, v. 2.0. If a copy of the MPL
+// was not distributed with this file, You can obtain one
+// at http://mozilla.org/MPL/2.0/.
+
+// Import and export Dung AFs represented using the ASPARTIX format, in
+// which the arguments and attacks are declared using facts of the form
+// arg(a). and att(a,b). Text from % to the end of the line is a comment.
+// Identifiers containing other characters than letters, digits and
+// underscores may be quoted, as in arg("a b"). Collective attacks, by
+// sets of arguments, are written as in att({a,b},c).
+//
+// Probabilistic AFs are represented by adding the probabilities of the
+// arguments and attacks to the facts, as in arg(a,0.7). and att(a,b,0.5).
+// Arguments and attacks without a probability have probability 1.
+// See <https://www.dbai.tuwien.ac.at/research/argumentation/aspartix/dung.html>
+package apx
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/carneades/carneades-4/src/engine/dung"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// A lexer for the tokens of APX files: identifiers, which may be quoted,
+// and the punctuation characters '(', ')', ',', '.', '{' and '}'
+type lexer struct {
+	reader *bufio.Reader
+	line   int
+}
+
+type token struct {
+	text   string
+	quoted bool
+}
+
+var eof = token{}
+
+func isPunctuation(c rune) bool {
+	return strings.ContainsRune("(),.{}", c)
+}
+
+// A token is punctuation, unless it is quoted
+func (t token) isPunctuation() bool {
+	return !t.quoted && len(t.text) == 1 && isPunctuation(rune(t.text[0]))
+}
+
+func (t token) String() string {
+	if t == eof {
+		return "end of file"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// Returns the next token, or eof at the end of the input
+func (l *lexer) next() (token, error) {
+	for {
+		c, _, err := l.reader.ReadRune()
+		if err == io.EOF {
+			return eof, nil
+		} else if err != nil {
+			return eof, err
+		}
+		switch {
+		case c == '\n':
+			l.line++
+		case unicode.IsSpace(c):
+		case c == '%':
+			// skip the comment
+			if _, err := l.reader.ReadString('\n'); err != nil && err != io.EOF {
+				return eof, err
+			}
+			l.line++
+		case isPunctuation(c):
+			return token{text: string(c)}, nil
+		case c == '"' || c == '\'':
+			// quoted identifier, in which a backslash escapes the next character
+			quote, line := c, l.line
+			id := []rune{}
+			for {
+				c, _, err := l.reader.ReadRune()
+				if err == io.EOF {
+					return eof, fmt.Errorf("line %d: unterminated quoted identifier", line)
+				} else if err != nil {
+					return eof, err
+				}
+				if c == quote {
+					break
+				}
+				if c == '\\' {
+					if c, _, err = l.reader.ReadRune(); err != nil {
+						return eof, fmt.Errorf("line %d: unterminated quoted identifier", line)
+					}
+				}
+				if c == '\n' {
+					l.line++
+				}
+				id = append(id, c)
+			}
+			return token{text: string(id), quoted: true}, nil
+		default:
+			id := []rune{c}
+			for {
+				c, _, err := l.reader.ReadRune()
+				if err == io.EOF {
+					break
+				} else if err != nil {
+					return eof, err
+				}
+				// the decimal point of a number
+				if c == '.' && isDigits(id) {
+					if b, err := l.reader.Peek(1); err == nil && '0' <= b[0] && b[0] <= '9' {
+						id = append(id, c)
+						continue
+					}
+				}
+				if unicode.IsSpace(c) || isPunctuation(c) || c == '%' || c == '"' || c == '\'' {
+					l.reader.UnreadRune()
+					break
+				}
+				id = append(id, c)
+			}
+			return token{text: string(id)}, nil
+		}
+	}
+}
+
+func isDigits(id []rune) bool {
+	for _, c := range id {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// An attack of a set of arguments on an argument
+type attack struct {
+	from []dung.Arg
+	to   dung.Arg
+	line int
+	prob string // the probability, if any
+}
+
+// Parses the facts of an APX file, returning the declared arguments and the
+// attacks. If sets is true, the attacker of an att fact may be a set of
+// arguments, as in att({a,b},c). If probs is true, the facts may include
+// probabilities, as in arg(a,0.7) and att(a,b,0.5), and the probabilities
+// of the arguments are returned too.
+func parse(inFile io.Reader, sets, probs bool) ([]dung.Arg, []attack, map[dung.Arg]string, error) {
+	l := &lexer{reader: bufio.NewReader(inFile), line: 1}
+	args := []dung.Arg{}
+	declared := make(map[dung.Arg]bool)
+	attacks := []attack{}
+	argProbs := make(map[dung.Arg]string)
+
+	expect := func(token string) error {
+		t, err := l.next()
+		if err != nil {
+			return err
+		}
+		if t.quoted || t.text != token {
+			return fmt.Errorf("line %d: expected %q, found %s", l.line, token, t)
+		}
+		return nil
+	}
+
+	identifier := func(t token) (dung.Arg, error) {
+		if t == eof || t.isPunctuation() {
+			return "", fmt.Errorf("line %d: expected an argument, found %s", l.line, t)
+		}
+		return dung.Arg(t.text), nil
+	}
+
+	nextIdentifier := func() (dung.Arg, error) {
+		t, err := l.next()
+		if err != nil {
+			return "", err
+		}
+		return identifier(t)
+	}
+
+	// an argument or, if sets is true, a set of arguments {a1,...,an}
+	attackers := func() ([]dung.Arg, error) {
+		t, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		if !sets || t.quoted || t.text != "{" {
+			a, err := identifier(t)
+			return []dung.Arg{a}, err
+		}
+		members := []dung.Arg{}
+		if t, err = l.next(); err != nil {
+			return nil, err
+		}
+		if !t.quoted && t.text == "}" {
+			return members, nil // the empty set
+		}
+		for {
+			a, err := identifier(t)
+			if err != nil {
+				return nil, err
+			}
+			members = append(members, a)
+			sep, err := l.next()
+			if err != nil {
+				return nil, err
+			}
+			if !sep.quoted && sep.text == "}" {
+				return members, nil
+			}
+			if sep.quoted || sep.text != "," {
+				return nil, fmt.Errorf("line %d: expected \",\" or \"}\", found %s", l.line, sep)
+			}
+			if t, err = l.next(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// an optional probability, following a comma, and the closing
+	// parenthesis of a fact
+	end := func() (string, error) {
+		t, err := l.next()
+		if err != nil {
+			return "", err
+		}
+		if !probs || t.quoted || t.text != "," {
+			if t.quoted || t.text != ")" {
+				return "", fmt.Errorf("line %d: expected %q, found %s", l.line, ")", t)
+			}
+			return "", nil
+		}
+		if t, err = l.next(); err != nil {
+			return "", err
+		}
+		if t == eof || t.isPunctuation() {
+			return "", fmt.Errorf("line %d: expected a probability, found %s", l.line, t)
+		}
+		return t.text, expect(")")
+	}
+
+	for {
+		t, err := l.next()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if t == eof {
+			break
+		}
+		if t.quoted {
+			return nil, nil, nil, fmt.Errorf("line %d: expected arg or att, found %s", l.line, t)
+		}
+		switch t.text {
+		case "arg":
+			if err = expect("("); err != nil {
+				return nil, nil, nil, err
+			}
+			a, err := nextIdentifier()
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			if !declared[a] {
+				declared[a] = true
+				args = append(args, a)
+			}
+			prob, err := end()
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			if prob != "" {
+				argProbs[a] = prob
+			}
+		case "att":
+			if err = expect("("); err != nil {
+				return nil, nil, nil, err
+			}
+			from, err := attackers()
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			if err = expect(","); err != nil {
+				return nil, nil, nil, err
+			}
+			b, err := nextIdentifier()
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			prob, err := end()
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			attacks = append(attacks, attack{from, b, l.line, prob})
+		default:
+			return nil, nil, nil, fmt.Errorf("line %d: expected arg or att, found %s", l.line, t)
+		}
+		if err = expect("."); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	// attacks may precede the declaration of their arguments
+	for _, atk := range attacks {
+		for _, a := range append([]dung.Arg{atk.to}, atk.from...) {
+			if !declared[a] {
+				return nil, nil, nil, fmt.Errorf("line %d: undeclared argument: %s", atk.line, a)
+			}
+		}
+	}
+	return args, attacks, argProbs, nil
+}
+
+func Import(inFile io.Reader) (af dung.AF, err error) {
+	args, attacks, _, err := parse(inFile, false, false)
+	if err != nil {
+		return af, err
+	}
+	atks := make(map[dung.Arg][]dung.Arg)
+	for _, atk := range attacks {
+		atks[atk.to] = append(atks[atk.to], atk.from[0])
+	}
+	return dung.NewAF(args, atks), nil
+}
+
+// Import an AF with collective attacks, in which attacks by sets of
+// arguments are represented by facts of the form att({a1,...,an},b).
+// Attacks by single arguments may also be written as att(a,b). An attack
+// by the empty set, att({},b), defeats b unconditionally.
+func ImportSETAF(inFile io.Reader) (f dung.SETAF, err error) {
+	args, attacks, _, err := parse(inFile, true, false)
+	if err != nil {
+		return f, err
+	}
+	atks := make(map[dung.Arg][][]dung.Arg)
+	for _, atk := range attacks {
+		atks[atk.to] = append(atks[atk.to], atk.from)
+	}
+	return dung.NewSETAF(args, atks), nil
+}
+
+// Import a probabilistic AF, in which the facts may include the
+// probabilities of the arguments and attacks, as in arg(a,0.7). and
+// att(a,b,0.5).
+func ImportPAF(inFile io.Reader) (p dung.PAF, err error) {
+	args, attacks, argProbs, err := parse(inFile, false, true)
+	if err != nil {
+		return p, err
+	}
+	atks := make(map[dung.Arg][]dung.Arg)
+	for _, atk := range attacks {
+		atks[atk.to] = append(atks[atk.to], atk.from[0])
+	}
+	p = dung.NewPAF(dung.NewAF(args, atks))
+	for _, arg := range args {
+		prob, ok := argProbs[arg]
+		if !ok {
+			continue
+		}
+		x, err := strconv.ParseFloat(prob, 64)
+		if err == nil {
+			err = p.SetArgProbability(arg, x)
+		}
+		if err != nil {
+			return p, fmt.Errorf("argument %s: %s", arg, err)
+		}
+	}
+	for _, atk := range attacks {
+		if atk.prob == "" {
+			continue
+		}
+		x, err := strconv.ParseFloat(atk.prob, 64)
+		if err == nil {
+			err = p.SetAttackProbability(atk.from[0], atk.to, x)
+		}
+		if err != nil {
+			return p, fmt.Errorf("line %d: %s", atk.line, err)
+		}
+	}
+	return p, nil
+}
+
+// Identifiers which can be exported without quotes: Prolog atoms and
+// numbers without leading zeros
+var plainIdentifier = regexp.MustCompile(`^([a-z][A-Za-z0-9_]*|0|[1-9][0-9]*)$`)
+
+func quote(arg dung.Arg) string {
+	if plainIdentifier.MatchString(string(arg)) {
+		return string(arg)
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + r.Replace(string(arg)) + `"`
+}
+
+// Export an AF, declaring its arguments in the order of af.Args(),
+// followed by its attacks. Attacks by arguments which are not arguments
+// of the AF are omitted.
+func Export(outFile io.Writer, af dung.AF) error {
+	args := af.Args()
+	declared := make(map[dung.Arg]bool, len(args))
+	for _, arg := range args {
+		declared[arg] = true
+	}
+	w := bufio.NewWriter(outFile)
+	for _, arg := range args {
+		fmt.Fprintf(w, "arg(%s).\n", quote(arg))
+	}
+	for _, arg := range args {
+		for _, atk := range af.Atks()[arg] {
+			if declared[atk] {
+				fmt.Fprintf(w, "att(%s,%s).\n", quote(atk), quote(arg))
+			}
+		}
+	}
+	return w.Flush()
+}
+
+// Export an AF with collective attacks, writing attacks by single
+// arguments as att(a,b) and attacks by other sets, including the empty
+// set, as att({a1,...,an},b), which ImportSETAF reads back
+func ExportSETAF(outFile io.Writer, f dung.SETAF) error {
+	args := f.Args()
+	w := bufio.NewWriter(outFile)
+	for _, arg := range args {
+		fmt.Fprintf(w, "arg(%s).\n", quote(arg))
+	}
+	for _, arg := range args {
+		for _, T := range f.Atks()[arg] {
+			if len(T) == 1 {
+				fmt.Fprintf(w, "att(%s,%s).\n", quote(T[0]), quote(arg))
+				continue
+			}
+			members := []string{}
+			for _, a := range T {
+				members = append(members, quote(a))
+			}
+			fmt.Fprintf(w, "att({%s},%s).\n", strings.Join(members, ","), quote(arg))
+		}
+	}
+	return w.Flush()
+}
@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"github.com/carneades/carneades-4/src/engine/dung"
 	"io"
+	"math"
 	"strings"
 )
 
@@ -216,7 +217,19 @@ func pEdges(w io.Writer, edges []Edge) {
 	}
 }
 
-func mkNodesAndEdges(af dung.AF, extension dung.ArgSet) (nodes []Node, edges []Edge, err error) {
+// The fill colors of arguments labelled in, out and undecided
+func labelColor(label dung.Label) string {
+	switch label {
+	case dung.In:
+		return green
+	case dung.Out:
+		return red
+	default:
+		return yellow
+	}
+}
+
+func mkNodesAndEdges(af dung.AF, l dung.Labelling) (nodes []Node, edges []Edge, err error) {
 	firstNode := true
 	firstEdge := true
 	// Arguments
@@ -224,9 +237,9 @@ func mkNodesAndEdges(af dung.AF, extension dung.ArgSet) (nodes []Node, edges []E
 		nNode := newNode()
 		nNode.nodeLabel = arg.String()
 		nNode.id = arg.String()
-		// color the node green if the argument is in the extension
-		if extension[arg] {
-			nNode.color = green
+		// color the node by the label of the argument, if labelled
+		if label, ok := l[arg]; ok {
+			nNode.color = labelColor(label)
 		}
 		if firstNode {
 			nodes = []Node{nNode}
@@ -252,11 +265,101 @@ func mkNodesAndEdges(af dung.AF, extension dung.ArgSet) (nodes []Node, edges []E
 	return
 }
 
+// Export an AF with the nodes filled by the labels of the arguments in
+// the labelling of the extension: green for in, red for out and yellow
+// for undecided. See dung.ExtensionLabelling.
 func Export(w io.Writer, af dung.AF, extension dung.ArgSet) error {
-	nodes, edges, err := mkNodesAndEdges(af, extension)
+	return ExportLabelling(w, af, af.ExtensionLabelling(extension))
+}
+
+// Export an AF with the nodes filled by the labels of the arguments:
+// green for in, red for out and yellow for undecided. Arguments which are
+// not labelled are filled white.
+func ExportLabelling(w io.Writer, af dung.AF, l dung.Labelling) error {
+	nodes, edges, err := mkNodesAndEdges(af, l)
+	if err != nil {
+		return err
+	}
+	graphNr++
+	pHead(w, graphNr)
+	pNodes(w, nodes)
+	pEdges(w, edges)
+	pFoot(w)
+	return nil
+}
+
+// Export an explanation of the acceptance of an argument. The dispute
+// tree of the explanation, if any, is shown with the arguments of the
+// proponent filled green and those of the opponent filled red. Arrows
+// point from attacking to attacked arguments, as in the AF. Otherwise
+// the AF is shown with the arguments of the witness or counter-extension
+// of the explanation labelled, as in Export.
+func ExportExplanation(w io.Writer, af dung.AF, e dung.Explanation) error {
+	if e.Tree == nil {
+		return Export(w, af, dung.NewArgSet(e.Extension...))
+	}
+	nodes := []Node{}
+	edges := []Edge{}
+	var mkTree func(t *dung.DisputeTree) Node
+	mkTree = func(t *dung.DisputeTree) Node {
+		node := newNode()
+		node.nodeLabel = t.Arg.String()
+		if t.Player == dung.Proponent {
+			node.color = green
+		} else {
+			node.color = red
+		}
+		if t.Repeated {
+			node.borderLine = line + "," + dashed
+		}
+		nodes = append(nodes, node)
+		for _, c := range t.Children {
+			child := mkTree(c)
+			edge := newEdge()
+			edge.source = child.id
+			edge.target = node.id
+			edges = append(edges, edge)
+		}
+		return node
+	}
+	mkTree(e.Tree)
+	graphNr++
+	pHead(w, graphNr)
+	pNodes(w, nodes)
+	pEdges(w, edges)
+	pFoot(w)
+	return nil
+}
+
+// Returns a color between white, for the degree 0, and green, for the
+// degree 1
+func shade(degree float64) string {
+	if degree < 0 {
+		degree = 0
+	} else if degree > 1 {
+		degree = 1
+	}
+	mix := func(from, to int) int {
+		return from + int(math.Round(degree*float64(to-from)))
+	}
+	// green is #3AB54A
+	return fmt.Sprintf("#%02X%02X%02X", mix(0xFF, 0x3A), mix(0xFF, 0xB5), mix(0xFF, 0x4A))
+}
+
+// Export an AF with the nodes shaded by the degrees of acceptability
+// of the arguments, from white, for 0, to green, for 1, as computed
+// by some gradual semantics.
+func ExportDegrees(w io.Writer, af dung.AF, degrees map[dung.Arg]float64) error {
+	nodes, edges, err := mkNodesAndEdges(af, nil)
 	if err != nil {
 		return err
 	}
+	for i, node := range nodes {
+		arg := af.Args()[i]
+		node.nodeLabel = fmt.Sprintf("%s (%.3f)", arg, degrees[arg])
+		node.color = shade(degrees[arg])
+		nodes[i] = node
+	}
 	graphNr++
 	pHead(w, graphNr)
 	pNodes(w, nodes)
@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"github.com/carneades/carneades-4/src/engine/dung"
 	"io"
+	"math"
 )
 
 func p(w io.Writer, strs ...string) {
@@ -31,7 +32,8 @@ func pHead(w io.Writer) {
 		"     xmlns:y=\"http://www.yworks.com/xml/graphml\" ",
 		"     xmlns:yed=\"http://www.yworks.com/xml/yed/3\" ",
 		"     xsi:schemaLocation=\"http://graphml.graphdrawing.org/xmlns http://www.yworks.com/xml/schema/graphml/1.1/ygraphml.xsd\"> ",
-		"<key for=\"node\" id=\"d5\" yfiles.type=\"nodegraphics\"/>")
+		"<key for=\"node\" id=\"d5\" yfiles.type=\"nodegraphics\"/>",
+		"<key for=\"edge\" id=\"d9\" yfiles.type=\"edgegraphics\"/>")
 	graphNr = 1
 }
 
@@ -39,17 +41,25 @@ func pFoot(w io.Writer) {
 	p(w, "</graphml>")
 }
 
-func pNodes(w io.Writer, arg []dung.Arg, extension dung.ArgSet) {
+// The fill colors of arguments labelled in, out and undecided
+func labelColor(label dung.Label) string {
+	switch label {
+	case dung.In:
+		return "#99cc00"
+	case dung.Out:
+		return "#ff6666"
+	default:
+		return "#ffcc00"
+	}
+}
+
+func pNodes(w io.Writer, arg []dung.Arg, l dung.Labelling) {
 	for _, node := range arg {
 		p(w, "   <node id=\""+string(node)+"\">",
 			"      <data key=\"d5\">",
-			"      <y:ShapeNode>")
-		if b, ok := extension[node]; ok && b {
-			p(w, "      <y:Fill color=\"#99cc00\" transparent=\"false\"/>")
-		} else {
-			p(w, "      <y:Fill color=\"#ffcc00\" transparent=\"false\"/>")
-		}
-		p(w, "      <y:NodeLabel >"+string(node)+"</y:NodeLabel>",
+			"      <y:ShapeNode>",
+			"      <y:Fill color=\""+labelColor(l.Get(node))+"\" transparent=\"false\"/>",
+			"      <y:NodeLabel >"+string(node)+"</y:NodeLabel>",
 			"       <y:Shape type=\"ellipse\"/>",
 			"       </y:ShapeNode>",
 			"       </data>",
@@ -71,12 +81,100 @@ func pEdges(w io.Writer, atks map[dung.Arg][]dung.Arg) {
 	}
 }
 
+// Export an AF with the nodes filled by the labels of the arguments in
+// the labelling of the extension: green for in, red for out and yellow
+// for undecided. See dung.ExtensionLabelling.
 func Export(w io.Writer, af dung.AF, extension dung.ArgSet) {
+	ExportLabelling(w, af, af.ExtensionLabelling(extension))
+}
+
+// Export an AF with the nodes filled by the labels of the arguments:
+// green for in, red for out and yellow for undecided.
+func ExportLabelling(w io.Writer, af dung.AF, l dung.Labelling) {
+	pHead(w)
+	p(w, "<graph edgedefault=\"directed\" id=\"G"+
+		fmt.Sprintf("%d", graphNr)+"\">")
+	graphNr++
+	pNodes(w, af.Args(), l)
+	pEdges(w, af.Atks())
+	p(w, "</graph>")
+	pFoot(w)
+}
+
+// Support edges are drawn as dashed green lines with white arrowheads
+func pSupportEdges(w io.Writer, sups map[dung.Arg][]dung.Arg) {
+	for target, nodes := range sups {
+		for i, source := range nodes {
+			p(w, "   <edge id=\"s-"+
+				string(target)+
+				fmt.Sprintf("-%d", i)+
+				"\" source=\""+
+				string(source)+
+				"\" target=\""+
+				string(target)+"\">",
+				"      <data key=\"d9\">",
+				"      <y:PolyLineEdge>",
+				"       <y:LineStyle color=\"#3AB54A\" type=\"dashed\" width=\"1.0\"/>",
+				"       <y:Arrows source=\"none\" target=\"white_delta\"/>",
+				"       </y:PolyLineEdge>",
+				"       </data>",
+				"   </edge>")
+		}
+	}
+}
+
+// Export a bipolar AF, distinguishing supports from attacks. The nodes
+// are labelled using the attacks of the BAF only.
+func ExportBAF(w io.Writer, baf dung.BAF, extension dung.ArgSet) {
+	pHead(w)
+	p(w, "<graph edgedefault=\"directed\" id=\"G"+
+		fmt.Sprintf("%d", graphNr)+"\">")
+	graphNr++
+	af := dung.NewAF(baf.Args(), baf.Atks())
+	pNodes(w, baf.Args(), af.ExtensionLabelling(extension))
+	pEdges(w, baf.Atks())
+	pSupportEdges(w, baf.Sups())
+	p(w, "</graph>")
+	pFoot(w)
+}
+
+// Returns a color between white, for the degree 0, and green, for the
+// degree 1
+func shade(degree float64) string {
+	if degree < 0 {
+		degree = 0
+	} else if degree > 1 {
+		degree = 1
+	}
+	mix := func(from, to int) int {
+		return from + int(math.Round(degree*float64(to-from)))
+	}
+	return fmt.Sprintf("#%02x%02x%02x", mix(0xff, 0x99), mix(0xff, 0xcc), mix(0xff, 0x00))
+}
+
+func pShadedNodes(w io.Writer, arg []dung.Arg, degrees map[dung.Arg]float64) {
+	for _, node := range arg {
+		p(w, "   <node id=\""+string(node)+"\">",
+			"      <data key=\"d5\">",
+			"      <y:ShapeNode>",
+			"      <y:Fill color=\""+shade(degrees[node])+"\" transparent=\"false\"/>",
+			"      <y:NodeLabel >"+string(node)+fmt.Sprintf(" (%.3f)", degrees[node])+"</y:NodeLabel>",
+			"       <y:Shape type=\"ellipse\"/>",
+			"       </y:ShapeNode>",
+			"       </data>",
+			"   </node>")
+	}
+}
+
+// Export an AF with the nodes shaded by the degrees of acceptability
+// of the arguments, from white, for 0, to green, for 1, as computed
+// by some gradual semantics.
+func ExportDegrees(w io.Writer, af dung.AF, degrees map[dung.Arg]float64) {
 	pHead(w)
 	p(w, "<graph edgedefault=\"directed\" id=\"G"+
 		fmt.Sprintf("%d", graphNr)+"\">")
 	graphNr++
-	pNodes(w, af.Args(), extension)
+	pShadedNodes(w, af.Args(), degrees)
 	pEdges(w, af.Atks())
 	p(w, "</graph>")
 	pFoot(w)
@@ -0,0 +1,113 @@
+// Copyright © 2015 The Carneades Authors
+// This Source Code Form is subject to the terms of the
+// Mozilla Public License, v. 2.0. If a copy of the MPL
+// was not distributed with this file, You can obtain one
+// at http://mozilla.org/MPL/2.0/.
+
+// Import and export Dung AFs represented using the numeric format of
+// the International Competition on Computational Models of Argumentation
+// (ICCMA 2023). The first line, "p af N", declares the arguments 1 to N.
+// Every further line, "a b", is an attack of argument a on argument b.
+// Lines beginning with "#" are comments.
+// See <https://iccma2023.github.io/rules.html>
+package i23
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/carneades/carneades-4/src/engine/dung"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Returns the argument with the given index, written canonically, e.g. 1
+// for 01, as in the AFs returned by Import. Strings which are not
+// integers are returned unchanged.
+func CanonicalArg(s string) dung.Arg {
+	if i, err := strconv.Atoi(s); err == nil {
+		return dung.Arg(strconv.Itoa(i))
+	}
+	return dung.Arg(s)
+}
+
+func Import(inFile io.Reader) (af dung.AF, err error) {
+	scanner := bufio.NewScanner(inFile)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	var args []dung.Arg
+	atks := make(map[dung.Arg][]dung.Arg)
+	header := false
+	n := 0
+	lineNr := 0
+
+	// parse the index of an argument
+	parseArg := func(s string) (dung.Arg, error) {
+		i, err := strconv.Atoi(s)
+		if err != nil || i < 1 || i > n {
+			return "", fmt.Errorf("line %d: not an argument: %s", lineNr, s)
+		}
+		return CanonicalArg(s), nil
+	}
+
+	for scanner.Scan() {
+		lineNr++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue // skip empty lines and comments
+		}
+		if !header {
+			if len(fields) != 3 || fields[0] != "p" || fields[1] != "af" {
+				return af, fmt.Errorf("line %d: expected p af N", lineNr)
+			}
+			n, err = strconv.Atoi(fields[2])
+			if err != nil || n < 0 {
+				return af, fmt.Errorf("line %d: not a number of arguments: %s", lineNr, fields[2])
+			}
+			args = make([]dung.Arg, 0, n)
+			for i := 1; i <= n; i++ {
+				args = append(args, dung.Arg(strconv.Itoa(i)))
+			}
+			header = true
+			continue
+		}
+		if len(fields) != 2 {
+			return af, fmt.Errorf("line %d: expected an attack: %s", lineNr, scanner.Text())
+		}
+		a, err := parseArg(fields[0])
+		if err != nil {
+			return af, err
+		}
+		b, err := parseArg(fields[1])
+		if err != nil {
+			return af, err
+		}
+		atks[b] = append(atks[b], a)
+	}
+	if err = scanner.Err(); err != nil {
+		return af, err
+	}
+	if !header {
+		return af, fmt.Errorf("missing p af N line")
+	}
+	return dung.NewAF(args, atks), nil
+}
+
+// Export an AF, numbering the arguments in the order of af.Args().
+// Returns the arguments, such that the i-th argument is numbered i+1.
+func Export(outFile io.Writer, af dung.AF) ([]dung.Arg, error) {
+	args := af.Args()
+	index := make(map[dung.Arg]int, len(args))
+	for i, arg := range args {
+		index[arg] = i + 1
+	}
+	w := bufio.NewWriter(outFile)
+	fmt.Fprintf(w, "p af %d\n", len(args))
+	for _, arg := range args {
+		for _, atk := range af.Atks()[arg] {
+			if i, ok := index[atk]; ok {
+				fmt.Fprintf(w, "%d %d\n", i, index[arg])
+			}
+		}
+	}
+	return args, w.Flush()
+}
@@ -4,8 +4,19 @@
 // was not distributed with this file, You can obtain one
 // at http://mozilla.org/MPL/2.0/.
 
-// Import Dung AFs represented using the Trivial Graph Format
+// Import and export Dung AFs represented using the Trivial Graph Format
 // See <https://en.wikipedia.org/wiki/Trivial_Graph_Format>
+//
+// Bipolar AFs are represented by labelling the edges for supports with
+// "support". Edges without a label, or labelled "attack", are attacks.
+//
+// AFs modified by edits, e.g. to enforce an extension, are exported with
+// the added attacks labelled "added", so that they are imported as
+// attacks.
+//
+// Probabilistic AFs are represented by labelling nodes and edges with
+// their probabilities, e.g. "a 0.7" and "a b 0.5". Nodes and edges
+// without a label have probability 1.
 package tgf
 
 import (
@@ -13,37 +24,178 @@ import (
 	"fmt"
 	"github.com/carneades/carneades-4/src/engine/dung"
 	"io"
+	"strconv"
 )
 
-func Import(inFile io.Reader) (af dung.AF, err error) {
+const supportLabel = "support"
+const addedLabel = "added"
+
+// Scans the lines of a TGF file, calling node for each node, with its id
+// and label, if any, and edge for each edge, with the ids of its nodes and
+// its label, if any. Only the first word of a label is passed. Empty and
+// invalid lines are skipped.
+func scan(inFile io.Reader, node func(id, label string), edge func(from, to, label string)) error {
 	reader := bufio.NewReader(inFile)
-	args := make([]dung.Arg, 0, 50)
-	atks := make(map[dung.Arg][]dung.Arg, 50)
 	nodeList := true // false if reading the list of edges has begun
-	var line, token1, token2 string
-	var n int
-	eof := false
-	for !eof {
-		token1, token2 = "", ""
-		line, err = reader.ReadString('\n')
-		if err == io.EOF {
-			err = nil // io.EOF isn't really an error
-			eof = true
-		} else if err != nil {
-			return af, err // finish immediately for real errors
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return err // finish immediately for real errors
 		}
-		n, _ = fmt.Sscan(line, &token1, &token2)
+		var token1, token2, token3 string
+		n, _ := fmt.Sscan(line, &token1, &token2, &token3)
 		if nodeList && n >= 1 {
 			if token1 == "#" {
 				nodeList = false // start of edges list
-				continue
+			} else {
+				node(token1, token2)
 			}
-			args = append(args, dung.Arg(token1))
 		} else if !nodeList && n >= 2 { // edges list
-			atks[dung.Arg(token2)] = append(atks[dung.Arg(token2)], dung.Arg(token1))
+			edge(token1, token2, token3)
+		}
+		if err == io.EOF {
+			return nil // io.EOF isn't really an error
+		}
+	}
+}
+
+func Import(inFile io.Reader) (af dung.AF, err error) {
+	args := make([]dung.Arg, 0, 50)
+	atks := make(map[dung.Arg][]dung.Arg, 50)
+	err = scan(inFile, func(id, label string) {
+		args = append(args, dung.Arg(id))
+	}, func(from, to, label string) {
+		atks[dung.Arg(to)] = append(atks[dung.Arg(to)], dung.Arg(from))
+	})
+	if err != nil {
+		return af, err
+	}
+	return dung.NewAF(args, atks), nil
+}
+
+// Import a bipolar AF, in which the edges labelled "support" are supports
+func ImportBAF(inFile io.Reader) (baf dung.BAF, err error) {
+	args := make([]dung.Arg, 0, 50)
+	atks := make(map[dung.Arg][]dung.Arg, 50)
+	sups := make(map[dung.Arg][]dung.Arg, 50)
+	err = scan(inFile, func(id, label string) {
+		args = append(args, dung.Arg(id))
+	}, func(from, to, label string) {
+		if label == supportLabel {
+			sups[dung.Arg(to)] = append(sups[dung.Arg(to)], dung.Arg(from))
 		} else {
-			continue // skip empty and invalid lines
+			atks[dung.Arg(to)] = append(atks[dung.Arg(to)], dung.Arg(from))
+		}
+	})
+	if err != nil {
+		return baf, err
+	}
+	return dung.NewBAF(args, atks, sups), nil
+}
+
+// Import a probabilistic AF, in which the labels of nodes and edges are
+// their probabilities
+func ImportPAF(inFile io.Reader) (p dung.PAF, err error) {
+	args := make([]dung.Arg, 0, 50)
+	atks := make(map[dung.Arg][]dung.Arg, 50)
+	argProbs := make(map[dung.Arg]string)
+	atkProbs := make(map[[2]dung.Arg]string)
+	err = scan(inFile, func(id, label string) {
+		args = append(args, dung.Arg(id))
+		if label != "" {
+			argProbs[dung.Arg(id)] = label
+		}
+	}, func(from, to, label string) {
+		atks[dung.Arg(to)] = append(atks[dung.Arg(to)], dung.Arg(from))
+		if label != "" {
+			atkProbs[[2]dung.Arg{dung.Arg(from), dung.Arg(to)}] = label
+		}
+	})
+	if err != nil {
+		return p, err
+	}
+	p = dung.NewPAF(dung.NewAF(args, atks))
+	for arg, label := range argProbs {
+		x, err := strconv.ParseFloat(label, 64)
+		if err == nil {
+			err = p.SetArgProbability(arg, x)
+		}
+		if err != nil {
+			return p, fmt.Errorf("argument %s: %s", arg, err)
+		}
+	}
+	for atk, label := range atkProbs {
+		x, err := strconv.ParseFloat(label, 64)
+		if err == nil {
+			err = p.SetAttackProbability(atk[0], atk[1], x)
+		}
+		if err != nil {
+			return p, fmt.Errorf("attack %s %s: %s", atk[0], atk[1], err)
+		}
+	}
+	return p, nil
+}
+
+func pNodes(w io.Writer, args []dung.Arg) {
+	for _, arg := range args {
+		fmt.Fprintf(w, "%s\n", arg)
+	}
+	fmt.Fprintf(w, "#\n")
+}
+
+func pEdges(w io.Writer, args []dung.Arg, edges map[dung.Arg][]dung.Arg, label string) {
+	for _, arg := range args {
+		for _, source := range edges[arg] {
+			if label == "" {
+				fmt.Fprintf(w, "%s %s\n", source, arg)
+			} else {
+				fmt.Fprintf(w, "%s %s %s\n", source, arg, label)
+			}
+		}
+	}
+}
+
+func Export(outFile io.Writer, af dung.AF) error {
+	w := bufio.NewWriter(outFile)
+	pNodes(w, af.Args())
+	pEdges(w, af.Args(), af.Atks(), "")
+	return w.Flush()
+}
+
+// Export a bipolar AF, labelling the edges for supports with "support"
+func ExportBAF(outFile io.Writer, baf dung.BAF) error {
+	w := bufio.NewWriter(outFile)
+	pNodes(w, baf.Args())
+	pEdges(w, baf.Args(), baf.Atks(), "")
+	pEdges(w, baf.Args(), baf.Sups(), supportLabel)
+	return w.Flush()
+}
+
+// Export the AF resulting from applying the edits to the AF, labelling
+// the added attacks with "added". The AF is not modified.
+func ExportEdits(outFile io.Writer, af dung.AF, edits []dung.Edit) error {
+	atks := make(map[dung.Arg][]dung.Arg)
+	for arg, attackers := range af.Atks() {
+		atks[arg] = append([]dung.Arg{}, attackers...)
+	}
+	edited := dung.NewAF(append([]dung.Arg{}, af.Args()...), atks)
+	edited.Apply(edits)
+	// split the attacks of the edited AF into old and added attacks
+	old := make(map[dung.Arg][]dung.Arg)
+	added := make(map[dung.Arg][]dung.Arg)
+	for _, arg := range edited.Args() {
+		attackers := dung.NewArgSet(af.Atks()[arg]...)
+		for _, atk := range edited.Atks()[arg] {
+			if attackers.Contains(atk) {
+				old[arg] = append(old[arg], atk)
+			} else {
+				added[arg] = append(added[arg], atk)
+			}
 		}
 	}
-	return dung.NewAF(args, atks), err
+	w := bufio.NewWriter(outFile)
+	pNodes(w, edited.Args())
+	pEdges(w, edited.Args(), old, "")
+	pEdges(w, edited.Args(), added, addedLabel)
+	return w.Flush()
 }
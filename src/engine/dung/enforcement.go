@@ -0,0 +1,273 @@
+// Copyright © 2015 The Carneades Authors
+// This Source Code Form is subject to the terms of the
+// Mozilla Public License, v. 2.0. If a copy of the MPL
+// was not distributed with this file, You can obtain one
+// at http://mozilla.org/MPL/2.0/.
+
+// Extension enforcement: minimal changes of an AF which make a set of
+// arguments an extension, or an argument credulously or skeptically
+// accepted.
+// See: Baumann, R. and Brewka, G. Expanding argumentation frameworks:
+// Enforcing and monotonicity results. COMMA 2010, and Coste-Marquis, S.
+// et al. Extension enforcement in abstract argumentation as an
+// optimization problem. IJCAI 2015.
+
+package dung
+
+import (
+	"errors"
+	"fmt"
+)
+
+type EditOp int
+
+const (
+	AddArgEdit EditOp = iota
+	RemoveArgEdit
+	AddAttackEdit
+	RemoveAttackEdit
+)
+
+// An edit of an AF. For edits of arguments, the argument is From and To
+// is empty.
+type Edit struct {
+	Op   EditOp
+	From Arg
+	To   Arg
+}
+
+func (e Edit) String() string {
+	switch e.Op {
+	case AddArgEdit:
+		return fmt.Sprintf("add %s", e.From)
+	case RemoveArgEdit:
+		return fmt.Sprintf("remove %s", e.From)
+	case AddAttackEdit:
+		return fmt.Sprintf("add %s -> %s", e.From, e.To)
+	default:
+		return fmt.Sprintf("remove %s -> %s", e.From, e.To)
+	}
+}
+
+// Applies the edits to the AF, destructively. See AddArg.
+func (af *AF) Apply(edits []Edit) {
+	for _, e := range edits {
+		switch e.Op {
+		case AddArgEdit:
+			af.AddArg(e.From)
+		case RemoveArgEdit:
+			af.RemoveArg(e.From)
+		case AddAttackEdit:
+			af.AddAttack(e.From, e.To)
+		case RemoveAttackEdit:
+			af.RemoveAttack(e.From, e.To)
+		}
+	}
+}
+
+// Returns a copy of the AF, which does not share its arguments and
+// attacks with the AF
+func (af *AF) clone() AF {
+	atks := make(map[Arg][]Arg)
+	for arg, attackers := range af.atks {
+		atks[arg] = append([]Arg{}, attackers...)
+	}
+	c := NewAF(append([]Arg{}, af.args...), atks)
+	c.solver = af.solver
+	return c
+}
+
+// The kinds of edits which may be used to enforce sets of arguments
+type EditKinds uint8
+
+const (
+	// adding and removing attacks between arguments of the AF
+	AttackEdits EditKinds = 1 << iota
+	// removing arguments of the AF, other than the arguments enforced
+	ArgumentEdits
+	// adding a new argument, and attacks by it on arguments of the AF, as
+	// in the normal expansions of Baumann and Brewka
+	ExpansionEdits
+)
+
+// The maximum number of edited AFs evaluated when searching for a minimal
+// list of edits, bounding the cost of the search
+var EnforcementLimit = 100000
+
+var (
+	// No list of edits of the given kinds enforces the arguments
+	ErrNotEnforceable = errors.New("not enforceable using edits of these kinds")
+	// The search was stopped after evaluating EnforcementLimit edited AFs
+	ErrEnforcementLimit = errors.New("enforcement search limit exceeded")
+)
+
+// Returns a name for a new argument, which is not an argument of the AF
+func (af *AF) newArg() Arg {
+	args := NewArgSet(af.args...)
+	arg := Arg("new")
+	for i := 1; args.Contains(arg); i++ {
+		arg = Arg(fmt.Sprintf("new%d", i))
+	}
+	return arg
+}
+
+// Returns the candidate edits of the given kinds for enforcing the
+// arguments in keep. To prune the search, only edits touching the
+// arguments in keep or their attackers are considered: removing an
+// attacker, adding or removing an attack on or by one of these arguments,
+// and adding a new argument attacking arguments not in keep. These edits
+// suffice to make a conflict-free set of arguments, which can be defended
+// against its attackers, an extension of the usual semantics.
+func (af *AF) enforcementCandidates(kinds EditKinds, keep ArgSet) []Edit {
+	R := keep.Copy() // the arguments in keep and their attackers
+	for arg := range keep {
+		for _, atk := range af.atks[arg] {
+			R[atk] = true
+		}
+	}
+	candidates := []Edit{}
+	if kinds&ArgumentEdits != 0 {
+		for _, arg := range af.args {
+			if R.Contains(arg) && !keep.Contains(arg) {
+				candidates = append(candidates, Edit{Op: RemoveArgEdit, From: arg})
+			}
+		}
+	}
+	if kinds&AttackEdits != 0 {
+		for _, to := range af.args {
+			attackers := NewArgSet(af.atks[to]...)
+			for _, from := range af.args {
+				if !R.Contains(from) && !R.Contains(to) {
+					continue
+				}
+				if attackers.Contains(from) {
+					candidates = append(candidates, Edit{Op: RemoveAttackEdit, From: from, To: to})
+				} else {
+					candidates = append(candidates, Edit{Op: AddAttackEdit, From: from, To: to})
+				}
+			}
+		}
+	}
+	if kinds&ExpansionEdits != 0 {
+		arg := af.newArg()
+		candidates = append(candidates, Edit{Op: AddArgEdit, From: arg})
+		for _, to := range af.args {
+			if !keep.Contains(to) {
+				candidates = append(candidates, Edit{Op: AddAttackEdit, From: arg, To: to})
+			}
+		}
+	}
+	return candidates
+}
+
+// Returns a minimal list of edits of the given kinds, i.e. a list with
+// the least number of edits among the candidates of
+// enforcementCandidates, such that the AF resulting from applying the
+// edits satisfies the goal. The arguments in keep are not removed. The
+// search tries all lists of candidates of increasing length, so its cost
+// is exponential in the number of edits, and polynomial, of the degree of
+// the number of edits, in the number of arguments. It is intended for
+// small AFs, or AFs which can be repaired with few edits. Returns
+// ErrEnforcementLimit if more than EnforcementLimit edited AFs would have
+// to be evaluated, and ErrNotEnforceable if no list of edits satisfies
+// the goal.
+func (af *AF) enforce(kinds EditKinds, keep ArgSet, goal func(af *AF) bool) ([]Edit, error) {
+	candidates := af.enforcementCandidates(kinds, keep)
+	added := NewArgSet() // new arguments
+	for _, e := range candidates {
+		if e.Op == AddArgEdit {
+			added[e.From] = true
+		}
+	}
+	// Attacks by new arguments require adding the arguments. The edits
+	// are ordered so that the arguments are added first.
+	valid := func(edits []Edit) bool {
+		args := NewArgSet()
+		for _, e := range edits {
+			if e.Op == AddArgEdit {
+				args[e.From] = true
+			} else if added.Contains(e.From) && !args.Contains(e.From) {
+				return false
+			}
+		}
+		return true
+	}
+	evaluated := 0
+	edits := []Edit{}
+	// choose k more edits from the candidates, starting at index i
+	var choose func(i, k int) (bool, error)
+	choose = func(i, k int) (bool, error) {
+		if k == 0 {
+			if !valid(edits) {
+				return false, nil
+			}
+			if evaluated++; evaluated > EnforcementLimit {
+				return false, ErrEnforcementLimit
+			}
+			c := af.clone()
+			c.Apply(edits)
+			return goal(&c), nil
+		}
+		for j := i; j <= len(candidates)-k; j++ {
+			edits = append(edits, candidates[j])
+			if ok, err := choose(j+1, k-1); ok || err != nil {
+				return ok, err
+			}
+			edits = edits[:len(edits)-1]
+		}
+		return false, nil
+	}
+	for k := 0; k <= len(candidates); k++ {
+		ok, err := choose(0, k)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return edits, nil
+		}
+	}
+	return nil, ErrNotEnforceable
+}
+
+// Returns a minimal list of edits of the given kinds which makes the set
+// S an extension of the AF, using the given semantics, if strict is true,
+// or a subset of some extension otherwise. New arguments added by
+// expansion edits are ignored when comparing extensions with S. See
+// enforce for the candidate edits, the cost of the search and the errors
+// returned.
+func (af *AF) EnforceExtension(s Semantics, S ArgSet, strict bool, kinds EditKinds) ([]Edit, error) {
+	args := NewArgSet(af.args...)
+	if !subset(S, args) {
+		return nil, ErrNotEnforceable
+	}
+	return af.enforce(kinds, S, func(af *AF) bool {
+		for _, E := range af.Extensions(s) {
+			E = intersection([]ArgSet{E, args})
+			if strict && E.Equals(S) || !strict && subset(S, E) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Returns a minimal list of edits of the given kinds which makes the
+// argument credulously inferred, i.e. non-strict enforcement of the
+// argument.
+func (af *AF) EnforceCredulous(s Semantics, arg Arg, kinds EditKinds) ([]Edit, error) {
+	return af.EnforceExtension(s, NewArgSet(arg), false, kinds)
+}
+
+// Returns a minimal list of edits of the given kinds which makes the
+// argument skeptically inferred. Since every argument is skeptically
+// inferred if there are no extensions, the AF resulting from the edits
+// is also required to have some extension.
+func (af *AF) EnforceSkeptical(s Semantics, arg Arg, kinds EditKinds) ([]Edit, error) {
+	if !NewArgSet(af.args...).Contains(arg) {
+		return nil, ErrNotEnforceable
+	}
+	return af.enforce(kinds, NewArgSet(arg), func(af *AF) bool {
+		_, ok := af.SomeExtension(s)
+		return ok && af.SkepticallyInferred(s, arg)
+	})
+}
@@ -0,0 +1,302 @@
+// Copyright © 2015 The Carneades Authors
+// This Source Code Form is subject to the terms of the
+// Mozilla Public License, v. 2.0. If a copy of the MPL
+// was not distributed with this file, You can obtain one
+// at http://mozilla.org/MPL/2.0/.
+
+// Standard and strong equivalence of AFs. Two AFs are standard equivalent
+// w.r.t. a semantics if they have the same extensions, and strongly
+// equivalent if they remain standard equivalent whenever the same
+// arguments and attacks are added to both, i.e. if they can be
+// interchanged in every context. Strong equivalence is decided by
+// comparing kernels of the AFs, which remove attacks which are
+// irrelevant for the semantics.
+// See: Oikarinen, E. and Woltran, S. Characterizing strong equivalence
+// for argumentation frameworks. Artificial Intelligence 175 (2011), and
+// Gaggl, S. A. and Woltran, S. The cf2 argumentation semantics revisited.
+// Journal of Logic and Computation 23 (2013).
+
+package dung
+
+// A witness of the non-equivalence of two AFs: an expansion, i.e. the
+// arguments and attacks added to both AFs, and an extension of one of the
+// expanded AFs which is not an extension of the other.
+type EquivalenceWitness struct {
+	Expansion AF     // without arguments, for standard equivalence
+	Extension ArgSet // nil if no witness was found
+	First     bool   // true if the extension is an extension of the first expanded AF
+}
+
+// Returns the AF with the arguments and attacks of both af and h
+func (af *AF) Expand(h AF) AF {
+	atks := make(map[Arg][]Arg)
+	for arg, attackers := range af.atks {
+		atks[arg] = append([]Arg{}, attackers...)
+	}
+	e := NewAF(append([]Arg{}, af.args...), atks)
+	e.solver = af.solver
+	for _, arg := range h.args {
+		e.AddArg(arg)
+	}
+	for arg, attackers := range h.atks {
+		for _, atk := range attackers {
+			e.AddAttack(atk, arg)
+		}
+	}
+	return e
+}
+
+// Returns true if the AFs have the same extensions, using the given
+// semantics. Otherwise also returns an extension of one of the AFs which
+// is not an extension of the other.
+func StandardEquivalent(s Semantics, af1, af2 *AF) (bool, EquivalenceWitness) {
+	l1, l2 := af1.Extensions(s), af2.Extensions(s)
+	for _, E := range l1 {
+		if !containsSet(l2, E) {
+			return false, EquivalenceWitness{Extension: E, First: true}
+		}
+	}
+	for _, E := range l2 {
+		if !containsSet(l1, E) {
+			return false, EquivalenceWitness{Extension: E, First: false}
+		}
+	}
+	return true, EquivalenceWitness{}
+}
+
+type attack struct {
+	from, to Arg
+}
+
+// Returns true if the attack is irrelevant, and removed from the kernel
+// of the AF for the semantics. Self attacks are never removed.
+func (af *AF) irrelevant(s Semantics, a attack, attacks map[attack]bool) bool {
+	if a.from == a.to {
+		return false
+	}
+	selfAttacking := func(x Arg) bool {
+		return attacks[attack{x, x}]
+	}
+	switch s {
+	case Stable, Stage:
+		// the stable kernel
+		return selfAttacking(a.from)
+	case Admissible, Preferred, SemiStable, Ideal, Eager:
+		// the admissible kernel
+		return selfAttacking(a.from) && (attacks[attack{a.to, a.from}] || selfAttacking(a.to))
+	case Grounded:
+		// the grounded kernel
+		return selfAttacking(a.to) && (attacks[attack{a.to, a.from}] || selfAttacking(a.from))
+	case Complete:
+		// the complete kernel
+		return selfAttacking(a.from) && selfAttacking(a.to)
+	case Naive:
+		// conflicts with self-attacking arguments
+		return selfAttacking(a.from) || selfAttacking(a.to)
+	default:
+		// For CF2 and stage2 semantics, strong equivalence is
+		// syntactic equality.
+		return false
+	}
+}
+
+func (af *AF) attacks() map[attack]bool {
+	attacks := make(map[attack]bool)
+	for arg, attackers := range af.atks {
+		for _, atk := range attackers {
+			attacks[attack{atk, arg}] = true
+		}
+	}
+	return attacks
+}
+
+// Returns the kernel of the AF for the given semantics: the AF without
+// the attacks which are irrelevant for the semantics. Two AFs are strongly
+// equivalent iff their kernels are equal. For naive semantics, only the
+// conflicts between arguments matter, so the kernel includes the reverse
+// of every attack which is not removed.
+func (af *AF) Kernel(s Semantics) AF {
+	attacks := af.attacks()
+	atks := make(map[Arg][]Arg)
+	seen := make(map[attack]bool)
+	add := func(a attack) {
+		if !seen[a] {
+			seen[a] = true
+			atks[a.to] = append(atks[a.to], a.from)
+		}
+	}
+	for _, arg := range af.args {
+		for _, atk := range af.atks[arg] {
+			a := attack{atk, arg}
+			if af.irrelevant(s, a, attacks) {
+				continue
+			}
+			add(a)
+			if s == Naive {
+				add(attack{arg, atk})
+			}
+		}
+	}
+	k := NewAF(af.args, atks)
+	k.solver = af.solver
+	return k
+}
+
+// Returns the differences of the kernels, as the arguments and attacks
+// of either kernel which are not in the other kernel.
+func kernelDifferences(k1, k2 AF) ([]Arg, []attack) {
+	args := []Arg{}
+	args1, args2 := NewArgSet(k1.args...), NewArgSet(k2.args...)
+	for _, arg := range k1.args {
+		if !args2.Contains(arg) {
+			args = append(args, arg)
+		}
+	}
+	for _, arg := range k2.args {
+		if !args1.Contains(arg) {
+			args = append(args, arg)
+		}
+	}
+	attacks := []attack{}
+	attacks1, attacks2 := k1.attacks(), k2.attacks()
+	for _, k := range []AF{k1, k2} {
+		for _, arg := range k.args {
+			for _, atk := range k.atks[arg] {
+				a := attack{atk, arg}
+				if attacks1[a] != attacks2[a] {
+					attacks = append(attacks, a)
+				}
+			}
+		}
+	}
+	return args, attacks
+}
+
+// Returns true if the AFs are strongly equivalent, using the given
+// semantics, i.e. if their kernels are equal. Otherwise also returns an
+// expansion of both AFs after which the expanded AFs have different
+// extensions, together with an extension of one of the expanded AFs
+// which is not an extension of the other, if such an expansion is found.
+//
+// The expansions searched focus on a single difference of the kernels,
+// an argument or an attack. They add the arguments of the difference, a
+// new argument z, which may attack all arguments of the AFs, except some
+// arguments of the difference, and at most three further attacks between
+// these arguments, z and a second new argument y.
+func StronglyEquivalent(s Semantics, af1, af2 *AF) (bool, EquivalenceWitness) {
+	args, attacks := kernelDifferences(af1.Kernel(s), af2.Kernel(s))
+	if len(args) == 0 && len(attacks) == 0 {
+		return true, EquivalenceWitness{}
+	}
+	if ok, w := StandardEquivalent(s, af1, af2); !ok {
+		w.Expansion = NewAF([]Arg{}, map[Arg][]Arg{})
+		return false, w
+	}
+	all := append([]Arg{}, af1.args...)
+	for _, arg := range af2.args {
+		if !NewArgSet(all...).Contains(arg) {
+			all = append(all, arg)
+		}
+	}
+	// new arguments, whose names differ from the names of the
+	// arguments of the AFs
+	y, z := Arg("y"), Arg("z")
+	for NewArgSet(all...).Contains(y) || NewArgSet(all...).Contains(z) {
+		y, z = "_"+y, "_"+z
+	}
+	foci := [][]Arg{}
+	for _, arg := range args {
+		foci = append(foci, []Arg{arg})
+	}
+	for _, a := range attacks {
+		if a.from == a.to {
+			foci = append(foci, []Arg{a.from})
+		} else {
+			foci = append(foci, []Arg{a.from, a.to})
+		}
+	}
+	for _, focus := range foci {
+		if w, ok := distinguish(s, af1, af2, all, focus, y, z); ok {
+			return false, w
+		}
+	}
+	return false, EquivalenceWitness{}
+}
+
+// Searches for an expansion which distinguishes the AFs, given a focus of
+// one or two arguments. See StronglyEquivalent.
+func distinguish(s Semantics, af1, af2 *AF, all, focus []Arg, y, z Arg) (EquivalenceWitness, bool) {
+	// the sets of arguments not attacked by z, or nil if z is not added:
+	// the subsets of the focus and the focus with one further argument
+	spared := [][]Arg{nil, focus}
+	for _, arg := range focus {
+		spared = append(spared, []Arg{arg})
+	}
+	if len(focus) > 1 {
+		spared = append(spared, []Arg{})
+	}
+	for _, arg := range all {
+		if !NewArgSet(focus...).Contains(arg) {
+			spared = append(spared, append(append([]Arg{}, focus...), arg))
+		}
+	}
+	var witness EquivalenceWitness
+	try := func(T []Arg, extra []attack) bool {
+		h := NewAF(append([]Arg{}, focus...), map[Arg][]Arg{})
+		if T != nil {
+			h.AddArg(z)
+			for _, arg := range all {
+				if !NewArgSet(T...).Contains(arg) {
+					h.AddAttack(z, arg)
+				}
+			}
+		}
+		for _, a := range extra {
+			h.AddArg(a.from)
+			h.AddArg(a.to)
+			h.AddAttack(a.from, a.to)
+		}
+		e1, e2 := af1.Expand(h), af2.Expand(h)
+		if ok, w := StandardEquivalent(s, &e1, &e2); !ok {
+			w.Expansion = h
+			witness = w
+			return true
+		}
+		return false
+	}
+	for _, T := range spared {
+		// the further attacks are between the spared arguments, the
+		// focus and the new arguments
+		nodes := append(append([]Arg{}, focus...), y, z)
+		for _, arg := range T {
+			if !NewArgSet(focus...).Contains(arg) {
+				nodes = append(nodes, arg)
+			}
+		}
+		pairs := []attack{}
+		for _, from := range nodes {
+			for _, to := range nodes {
+				pairs = append(pairs, attack{from, to})
+			}
+		}
+		if try(T, nil) {
+			return witness, true
+		}
+		for i, a := range pairs {
+			if try(T, []attack{a}) {
+				return witness, true
+			}
+			for j, b := range pairs[i+1:] {
+				if try(T, []attack{a, b}) {
+					return witness, true
+				}
+				for _, c := range pairs[i+j+2:] {
+					if try(T, []attack{a, b, c}) {
+						return witness, true
+					}
+				}
+			}
+		}
+	}
+	return witness, false
+}
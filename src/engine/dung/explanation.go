@@ -0,0 +1,277 @@
+// Copyright © 2015 The Carneades Authors
+// This Source Code Form is subject to the terms of the
+// Mozilla Public License, v. 2.0. If a copy of the MPL
+// was not distributed with this file, You can obtain one
+// at http://mozilla.org/MPL/2.0/.
+
+// Explanations of the acceptance of arguments, using dispute trees
+// and witness or counter-extensions.
+
+package dung
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// The players of a dispute: the proponent defends an argument and the
+// opponent attacks it.
+type Player int
+
+const (
+	Proponent Player = iota
+	Opponent
+)
+
+func (p Player) String() string {
+	if p == Proponent {
+		return "P"
+	}
+	return "O"
+}
+
+func (p Player) MarshalText() ([]byte, error) {
+	if p == Proponent {
+		return []byte("proponent"), nil
+	}
+	return []byte("opponent"), nil
+}
+
+// A dispute tree, representing a winning strategy of the proponent.
+// The children of a proponent node are opponent nodes for all the
+// attackers of its argument. The only child of an opponent node is a
+// proponent node for an argument attacking the argument of the opponent.
+// The children of a proponent node are omitted if its argument has been
+// defended elsewhere in the tree, in which case the node is marked as
+// repeated.
+type DisputeTree struct {
+	Arg      Arg            `json:"arg"`
+	Player   Player         `json:"player"`
+	Repeated bool           `json:"repeated,omitempty"`
+	Children []*DisputeTree `json:"children,omitempty"`
+}
+
+// An explanation of the answer to a credulous or skeptical query about
+// the acceptance of an argument.
+//
+// For grounded semantics, and for skeptical queries under complete
+// semantics, the tree of an accepted argument is a winning strategy in
+// the grounded discussion game. For credulous queries under admissible,
+// complete and preferred semantics, the tree of an accepted argument
+// is an admissible dispute tree. The extension is either a witness, i.e.
+// an extension containing a credulously accepted argument, or a
+// counter-extension, i.e. an extension not containing an argument which
+// is not skeptically accepted.
+type Explanation struct {
+	Arg       Arg          `json:"arg"`
+	Semantics Semantics    `json:"semantics"`
+	Skeptical bool         `json:"skeptical"`
+	Accepted  bool         `json:"accepted"`
+	Tree      *DisputeTree `json:"tree,omitempty"`
+	Extension []Arg        `json:"extension"` // nil if there is none
+}
+
+func (s Semantics) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Returns the members of the set in the order of the arguments of the AF
+func (af *AF) ordered(E ArgSet) []Arg {
+	args := []Arg{}
+	for _, arg := range af.args {
+		if E.Contains(arg) {
+			args = append(args, arg)
+		}
+	}
+	return args
+}
+
+// Explain whether the argument is credulously inferred, i.e. a member
+// of some extension with the given semantics.
+func (af *AF) ExplainCredulous(s Semantics, arg Arg) Explanation {
+	e := Explanation{Arg: arg, Semantics: s}
+	switch s {
+	case Grounded:
+		return af.explainGrounded(e)
+	case Admissible, Complete, Preferred:
+		if E, ok := af.CredulousWitness(s, arg); ok {
+			e.Accepted = true
+			e.Extension = af.ordered(E)
+			e.Tree = af.disputeTree(arg, E, af.groundedRanks())
+		}
+	default:
+		if E, ok := af.CredulousWitness(s, arg); ok {
+			e.Accepted = true
+			e.Extension = af.ordered(E)
+		}
+	}
+	return e
+}
+
+// Explain whether the argument is skeptically inferred, i.e. a member
+// of every extension with the given semantics.
+func (af *AF) ExplainSkeptical(s Semantics, arg Arg) Explanation {
+	e := Explanation{Arg: arg, Semantics: s, Skeptical: true}
+	switch s {
+	case Grounded, Complete:
+		// The grounded extension is the least complete extension
+		return af.explainGrounded(e)
+	default:
+		if E, ok := af.SkepticalCounterexample(s, arg); ok {
+			e.Extension = af.ordered(E)
+		} else {
+			e.Accepted = true
+		}
+	}
+	return e
+}
+
+func (af *AF) explainGrounded(e Explanation) Explanation {
+	ranks := af.groundedRanks()
+	E := NewArgSet()
+	for arg := range ranks {
+		E[arg] = true
+	}
+	e.Extension = af.ordered(E)
+	if E.Contains(e.Arg) {
+		e.Accepted = true
+		e.Tree = af.disputeTree(e.Arg, E, ranks)
+	}
+	return e
+}
+
+// Computes the grounded extension, mapping each of its members to the
+// round of the iterative computation of the grounded labelling in which
+// it was labelled in. In each round, arguments all of whose attackers
+// were labelled out in earlier rounds are labelled in, and arguments
+// with an attacker labelled in in an earlier round are labelled out.
+func (af *AF) groundedRanks() map[Arg]int {
+	l := NewLabelling()
+	ranks := make(map[Arg]int)
+	for round := 1; ; round++ {
+		next := NewLabelling()
+		for _, arg := range af.args {
+			if _, found := l[arg]; found {
+				continue
+			}
+			allOut := true
+			for _, atk := range af.atks[arg] {
+				switch l.Get(atk) {
+				case In:
+					next[arg] = Out
+				case Undecided:
+					allOut = false
+				}
+			}
+			if _, found := next[arg]; !found && allOut {
+				next[arg] = In
+				ranks[arg] = round
+			}
+		}
+		if len(next) == 0 {
+			return ranks
+		}
+		for arg, label := range next {
+			l[arg] = label
+		}
+	}
+}
+
+// Constructs a dispute tree for an argument in the admissible set E.
+// Each attacker is countered by an attacker in E, preferring members of
+// the grounded extension with the lowest rank, so that for arguments in
+// the grounded extension the tree is a winning strategy in the grounded
+// discussion game.
+func (af *AF) disputeTree(arg Arg, E ArgSet, ranks map[Arg]int) *DisputeTree {
+	expanded := NewArgSet()
+	// the defender of an argument of the opponent
+	defender := func(b Arg) Arg {
+		var c Arg
+		found := false
+		for _, atk := range af.atks[b] {
+			if !E.Contains(atk) {
+				continue
+			}
+			if !found {
+				c, found = atk, true
+				continue
+			}
+			r1, ok1 := ranks[atk]
+			r2, ok2 := ranks[c]
+			if ok1 && (!ok2 || r1 < r2) {
+				c = atk
+			}
+		}
+		return c
+	}
+	var proponent func(a Arg) *DisputeTree
+	proponent = func(a Arg) *DisputeTree {
+		t := &DisputeTree{Arg: a, Player: Proponent}
+		if expanded.Contains(a) {
+			t.Repeated = true
+			return t
+		}
+		expanded[a] = true
+		seen := NewArgSet()
+		for _, b := range af.atks[a] {
+			if seen.Contains(b) {
+				continue
+			}
+			seen[b] = true
+			o := &DisputeTree{Arg: b, Player: Opponent}
+			o.Children = []*DisputeTree{proponent(defender(b))}
+			t.Children = append(t.Children, o)
+		}
+		return t
+	}
+	return proponent(arg)
+}
+
+func (e Explanation) String() string {
+	var b strings.Builder
+	e.WriteText(&b)
+	return b.String()
+}
+
+// Writes the explanation as indented plain text
+func (e Explanation) WriteText(w io.Writer) {
+	mode := "credulously"
+	if e.Skeptical {
+		mode = "skeptically"
+	}
+	not := ""
+	if !e.Accepted {
+		not = "not "
+	}
+	fmt.Fprintf(w, "%s is %s%s inferred using %s semantics.\n", e.Arg, not, mode, e.Semantics)
+	if e.Tree != nil {
+		fmt.Fprintf(w, "Dispute tree:\n")
+		var pTree func(t *DisputeTree, depth int)
+		pTree = func(t *DisputeTree, depth int) {
+			repeated := ""
+			if t.Repeated {
+				repeated = " (defended above)"
+			}
+			fmt.Fprintf(w, "%s%s: %s%s\n", strings.Repeat("  ", depth+1), t.Player, t.Arg, repeated)
+			for _, c := range t.Children {
+				pTree(c, depth+1)
+			}
+		}
+		pTree(e.Tree, 0)
+	}
+	if e.Extension != nil {
+		kind := "Witness"
+		switch {
+		case e.Semantics == Grounded || e.Skeptical && e.Semantics == Complete:
+			kind = "Grounded extension"
+		case !e.Accepted:
+			kind = "Counter-extension"
+		}
+		args := []string{}
+		for _, arg := range e.Extension {
+			args = append(args, string(arg))
+		}
+		fmt.Fprintf(w, "%s: [%s]\n", kind, strings.Join(args, ","))
+	}
+}
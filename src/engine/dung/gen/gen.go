@@ -0,0 +1,220 @@
+// Copyright © 2015 The Carneades Authors
+// This Source Code Form is subject to the terms of the
+// Mozilla Public License, v. 2.0. If a copy of the MPL
+// was not distributed with this file, You can obtain one
+// at http://mozilla.org/MPL/2.0/.
+
+// Generators of random and structured Dung AFs, for benchmarking and
+// testing solvers. The generators use the random number generator passed
+// to them, so that the AFs generated from a seeded generator can be
+// reproduced. The arguments are named a0, a1, ..., so that the AFs can be
+// exported in the tgf and apx formats.
+//
+// Except for the Erdős–Rényi generator, the generators construct
+// undirected graphs, and each edge {a,b} of the graph is turned into an
+// attack of a on b or of b on a, chosen at random.
+
+package gen
+
+import (
+	"fmt"
+	"github.com/carneades/carneades-4/src/engine/dung"
+	"math/rand"
+)
+
+// A set of attacks, in the order of their addition
+type attacks struct {
+	args []dung.Arg
+	atks map[dung.Arg][]dung.Arg
+	seen map[[2]int]bool
+}
+
+func newAttacks(n int) *attacks {
+	a := &attacks{atks: make(map[dung.Arg][]dung.Arg), seen: make(map[[2]int]bool)}
+	for i := 0; i < n; i++ {
+		a.args = append(a.args, dung.Arg(fmt.Sprintf("a%d", i)))
+	}
+	return a
+}
+
+// Adds the attack of the i-th argument on the j-th argument, unless it
+// exists already
+func (a *attacks) add(i, j int) {
+	if a.seen[[2]int{i, j}] {
+		return
+	}
+	a.seen[[2]int{i, j}] = true
+	a.atks[a.args[j]] = append(a.atks[a.args[j]], a.args[i])
+}
+
+// Adds an attack between the i-th and j-th arguments, in a random
+// direction
+func (a *attacks) addEdge(r *rand.Rand, i, j int) {
+	if r.Intn(2) == 0 {
+		a.add(i, j)
+	} else {
+		a.add(j, i)
+	}
+}
+
+func (a *attacks) af() dung.AF {
+	return dung.NewAF(a.args, a.atks)
+}
+
+// Generates an AF with n arguments in which each argument attacks each
+// other argument with probability p. There are no self attacks.
+func ErdosRenyi(r *rand.Rand, n int, p float64) dung.AF {
+	a := newAttacks(n)
+	for j := 0; j < n; j++ {
+		for i := 0; i < n; i++ {
+			if i != j && r.Float64() < p {
+				a.add(i, j)
+			}
+		}
+	}
+	return a.af()
+}
+
+// Generates a scale-free AF with n arguments by preferential attachment.
+// Each argument after the first is connected to m distinct preceding
+// arguments, or all preceding arguments if there are fewer, chosen with
+// probability proportional to their degree plus one. Negative values of n
+// and m are treated as 0.
+func BarabasiAlbert(r *rand.Rand, n, m int) dung.AF {
+	if n < 0 {
+		n = 0
+	}
+	if m < 0 {
+		m = 0
+	}
+	a := newAttacks(n)
+	degree := make([]int, n)
+	for i := 1; i < n; i++ {
+		k := m
+		if k > i {
+			k = i
+		}
+		chosen := make(map[int]bool)
+		for len(chosen) < k {
+			total := 0
+			for j := 0; j < i; j++ {
+				if !chosen[j] {
+					total += degree[j] + 1
+				}
+			}
+			x := r.Intn(total)
+			for j := 0; j < i; j++ {
+				if chosen[j] {
+					continue
+				}
+				x -= degree[j] + 1
+				if x < 0 {
+					chosen[j] = true
+					break
+				}
+			}
+		}
+		for j := 0; j < i; j++ {
+			if chosen[j] {
+				a.addEdge(r, i, j)
+				degree[i]++
+				degree[j]++
+			}
+		}
+	}
+	return a.af()
+}
+
+// Generates an AF whose arguments form a grid with the given number of
+// rows and columns, in which each argument is connected to its
+// horizontal and vertical neighbours. Negative values of rows and cols
+// are treated as 0.
+func Grid(r *rand.Rand, rows, cols int) dung.AF {
+	if rows < 0 {
+		rows = 0
+	}
+	if cols < 0 {
+		cols = 0
+	}
+	a := newAttacks(rows * cols)
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			k := i*cols + j
+			if j+1 < cols {
+				a.addEdge(r, k, k+1)
+			}
+			if i+1 < rows {
+				a.addEdge(r, k, k+cols)
+			}
+		}
+	}
+	return a.af()
+}
+
+// Generates a small-world AF with n arguments. The arguments are placed
+// on a ring, and each argument is connected to its k/2 nearest neighbours
+// on each side. Then each edge is rewired with probability beta, by
+// replacing its second argument with an argument chosen uniformly at
+// random, avoiding self attacks and duplicate edges. An odd k is rounded
+// down, a k of n or more is reduced to the greatest even number less than
+// n, and negative values of n and k are treated as 0.
+func WattsStrogatz(r *rand.Rand, n, k int, beta float64) dung.AF {
+	if n < 0 {
+		n = 0
+	}
+	if k >= n {
+		k = n - 1
+	}
+	if k < 0 {
+		k = 0
+	}
+	k -= k % 2
+	a := newAttacks(n)
+	edges := [][2]int{}
+	adjacent := make(map[[2]int]bool)
+	connect := func(i, j int) {
+		edges = append(edges, [2]int{i, j})
+		adjacent[[2]int{i, j}] = true
+		adjacent[[2]int{j, i}] = true
+	}
+	for i := 0; i < n; i++ {
+		for d := 1; d <= k/2; d++ {
+			connect(i, (i+d)%n)
+		}
+	}
+	for e, edge := range edges {
+		i, j := edge[0], edge[1]
+		if r.Float64() >= beta {
+			continue
+		}
+		// the number of arguments which are not yet neighbours of i
+		free := 0
+		for l := 0; l < n; l++ {
+			if l != i && !adjacent[[2]int{i, l}] {
+				free++
+			}
+		}
+		if free == 0 {
+			continue
+		}
+		x := r.Intn(free)
+		for l := 0; l < n; l++ {
+			if l == i || adjacent[[2]int{i, l}] {
+				continue
+			}
+			if x == 0 {
+				delete(adjacent, [2]int{i, j})
+				delete(adjacent, [2]int{j, i})
+				adjacent[[2]int{i, l}] = true
+				adjacent[[2]int{l, i}] = true
+				edges[e] = [2]int{i, l}
+				break
+			}
+			x--
+		}
+	}
+	for _, edge := range edges {
+		a.addEdge(r, edge[0], edge[1])
+	}
+	return a.af()
+}
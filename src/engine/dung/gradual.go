@@ -0,0 +1,249 @@
+// Copyright © 2015 The Carneades Authors
+// This Source Code Form is subject to the terms of the
+// Mozilla Public License, v. 2.0. If a copy of the MPL
+// was not distributed with this file, You can obtain one
+// at http://mozilla.org/MPL/2.0/.
+
+// Gradual semantics, assigning acceptability degrees in the interval
+// [0,1] to arguments, and rankings of arguments by their degrees.
+// See: Amgoud, L. and Ben-Naim, J. Ranking-based semantics for
+// argumentation frameworks. SUM 2013, and Bonzon, E. et al. A comparative
+// study of ranking-based semantics for abstract argumentation. AAAI 2016.
+
+package dung
+
+import (
+	"math"
+	"sort"
+)
+
+type GradualSemantics int
+
+const (
+	// h-categorizer: 1/(1 + the sum of the degrees of the attackers)
+	HCategorizer GradualSemantics = iota
+	// max-based: 1/(1 + the maximum degree of the attackers)
+	MaxBased
+	// card-based: 1/(1 + the number of attackers + the mean degree of
+	// the attackers)
+	CardBased
+	// counting: 1 - the damped and normalized sum of the degrees of the
+	// attackers, where the damping factor is DefaultDamping
+	Counting
+)
+
+func (g GradualSemantics) String() string {
+	switch g {
+	case HCategorizer:
+		return "h-categorizer"
+	case MaxBased:
+		return "max-based"
+	case CardBased:
+		return "card-based"
+	case Counting:
+		return "counting"
+	default:
+		return "unknown"
+	}
+}
+
+// The damping factor of the counting semantics
+const DefaultDamping = 0.9
+
+// The degrees are computed by iterating the defining equations until
+// no degree changes by more than epsilon.
+const epsilon = 1e-9
+
+// Returns the attackers of each argument of the AF, without duplicates.
+// Only attacks by arguments of the AF are considered.
+func (af *AF) gradualAttackers() map[Arg][]Arg {
+	args := NewArgSet(af.args...)
+	attackers := make(map[Arg][]Arg)
+	for _, arg := range af.args {
+		seen := NewArgSet()
+		for _, atk := range af.atks[arg] {
+			if args.Contains(atk) && !seen.Contains(atk) {
+				seen[atk] = true
+				attackers[arg] = append(attackers[arg], atk)
+			}
+		}
+	}
+	return attackers
+}
+
+// Iterates the update function, starting with the degree 1 for
+// every argument, until a fixpoint is reached. Returns the degrees
+// of the arguments of the AF.
+func (af *AF) iterate(update func(arg Arg, attackers []Arg, v map[Arg]float64) float64) map[Arg]float64 {
+	attackers := af.gradualAttackers()
+	v := make(map[Arg]float64)
+	for _, arg := range af.args {
+		v[arg] = 1
+	}
+	for i := 0; i < 100000; i++ {
+		next := make(map[Arg]float64)
+		delta := 0.0
+		for _, arg := range af.args {
+			next[arg] = update(arg, attackers[arg], v)
+			delta = math.Max(delta, math.Abs(next[arg]-v[arg]))
+		}
+		v = next
+		if delta < epsilon {
+			break
+		}
+	}
+	return v
+}
+
+// Returns the degrees of acceptability of the arguments of the AF, using
+// the given gradual semantics.
+func (af *AF) Degrees(g GradualSemantics) map[Arg]float64 {
+	switch g {
+	case MaxBased:
+		return af.MaxBasedDegrees()
+	case CardBased:
+		return af.CardBasedDegrees()
+	case Counting:
+		return af.CountingDegrees(DefaultDamping)
+	default:
+		return af.HCategorizerDegrees()
+	}
+}
+
+func (af *AF) HCategorizerDegrees() map[Arg]float64 {
+	return af.iterate(func(arg Arg, attackers []Arg, v map[Arg]float64) float64 {
+		sum := 0.0
+		for _, b := range attackers {
+			sum += v[b]
+		}
+		return 1 / (1 + sum)
+	})
+}
+
+func (af *AF) MaxBasedDegrees() map[Arg]float64 {
+	return af.iterate(func(arg Arg, attackers []Arg, v map[Arg]float64) float64 {
+		max := 0.0
+		for _, b := range attackers {
+			max = math.Max(max, v[b])
+		}
+		return 1 / (1 + max)
+	})
+}
+
+func (af *AF) CardBasedDegrees() map[Arg]float64 {
+	return af.iterate(func(arg Arg, attackers []Arg, v map[Arg]float64) float64 {
+		if len(attackers) == 0 {
+			return 1
+		}
+		sum := 0.0
+		for _, b := range attackers {
+			sum += v[b]
+		}
+		n := float64(len(attackers))
+		return 1 / (1 + n + sum/n)
+	})
+}
+
+// The counting semantics, in which the degree of an argument is 1 minus
+// the sum of the degrees of its attackers, damped by the factor alpha,
+// in the interval (0,1), and normalized by the maximum number of
+// attackers of an argument. The degree thus counts the attackers and
+// defenders of the argument, with attack paths of length n weighted by
+// alpha to the power of n.
+// See: Pu, F. et al. Argument ranking with categoriser function. KSEM 2014.
+func (af *AF) CountingDegrees(alpha float64) map[Arg]float64 {
+	norm := 1.0
+	for _, attackers := range af.gradualAttackers() {
+		norm = math.Max(norm, float64(len(attackers)))
+	}
+	return af.iterate(func(arg Arg, attackers []Arg, v map[Arg]float64) float64 {
+		sum := 0.0
+		for _, b := range attackers {
+			sum += v[b]
+		}
+		return 1 - alpha*sum/norm
+	})
+}
+
+// Compares the degrees of two arguments. Returns 1 if a1 is more
+// acceptable than a2, -1 if it is less acceptable and 0 if their degrees
+// differ by less than the precision of the degrees.
+func Compare(degrees map[Arg]float64, a1, a2 Arg) int {
+	d := degrees[a1] - degrees[a2]
+	switch {
+	case d > 10*epsilon:
+		return 1
+	case d < -10*epsilon:
+		return -1
+	default:
+		return 0
+	}
+}
+
+// Ranks the arguments by their degrees. Returns the classes of arguments
+// with the same degree, ordered from the most to the least acceptable
+// arguments. Arguments whose degree differs from the next greater degree
+// by less than the precision of the degrees, as by Compare, are in the
+// same class. The arguments in each class are sorted by name.
+func Rank(degrees map[Arg]float64) [][]Arg {
+	args := []Arg{}
+	for arg := range degrees {
+		args = append(args, arg)
+	}
+	// sort by the exact degrees, since comparing with a tolerance is not
+	// transitive, and apply the tolerance only to group the arguments
+	sort.Slice(args, func(i, j int) bool {
+		if degrees[args[i]] != degrees[args[j]] {
+			return degrees[args[i]] > degrees[args[j]]
+		}
+		return args[i] < args[j]
+	})
+	ranking := [][]Arg{}
+	for i, arg := range args {
+		if i > 0 && Compare(degrees, args[i-1], arg) == 0 {
+			ranking[len(ranking)-1] = append(ranking[len(ranking)-1], arg)
+		} else {
+			ranking = append(ranking, []Arg{arg})
+		}
+	}
+	for _, class := range ranking {
+		sort.Slice(class, func(i, j int) bool { return class[i] < class[j] })
+	}
+	return ranking
+}
+
+// Measures the agreement of the rankings of two gradual semantics, as
+// the Kendall tau rank correlation of their degrees. Returns a number in
+// [-1,1]: 1 if the rankings order every pair of arguments alike and -1 if
+// they order every pair of arguments in reverse. Pairs of arguments which
+// are ranked equally by both rankings are ignored. Pairs ranked equally by
+// only one of the rankings count neither as agreements nor as
+// disagreements.
+func RankingCorrelation(degrees1, degrees2 map[Arg]float64) float64 {
+	args := []Arg{}
+	for arg := range degrees1 {
+		if _, found := degrees2[arg]; found {
+			args = append(args, arg)
+		}
+	}
+	agreement, pairs := 0.0, 0.0
+	for i, a1 := range args {
+		for _, a2 := range args[i+1:] {
+			c1, c2 := Compare(degrees1, a1, a2), Compare(degrees2, a1, a2)
+			if c1 == 0 && c2 == 0 {
+				continue
+			}
+			pairs++
+			switch {
+			case c1 == c2:
+				agreement++
+			case c1 == -c2:
+				agreement--
+			}
+		}
+	}
+	if pairs == 0 {
+		return 1
+	}
+	return agreement / pairs
+}
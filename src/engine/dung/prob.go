@@ -0,0 +1,254 @@
+// Copyright © 2015 The Carneades Authors
+// This Source Code Form is subject to the terms of the
+// Mozilla Public License, v. 2.0. If a copy of the MPL
+// was not distributed with this file, You can obtain one
+// at http://mozilla.org/MPL/2.0/.
+
+// Probabilistic AFs, in which the arguments and attacks are present with
+// independent probabilities. The probability that an argument is
+// accepted is the sum of the probabilities of the AFs induced by the
+// present arguments and attacks in which the argument is accepted.
+// See: Li, H., Oren, N. and Norman, T. J. Probabilistic argumentation
+// frameworks. TAFA 2011.
+
+package dung
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+type PAF struct {
+	af       AF
+	argProbs map[Arg]float64
+	atkProbs map[attack]float64
+}
+
+// Returns a probabilistic AF in which all arguments and attacks of the
+// AF are certain, i.e. have probability 1
+func NewPAF(af AF) PAF {
+	return PAF{af: af, argProbs: make(map[Arg]float64), atkProbs: make(map[attack]float64)}
+}
+
+func (p *PAF) AF() AF {
+	return p.af
+}
+
+// Select the solver used to compute the extensions of the induced AFs
+func (p *PAF) SetSolver(s Solver) {
+	p.af.solver = s
+}
+
+func checkProbability(x float64) error {
+	if x < 0 || x > 1 {
+		return fmt.Errorf("probability not between 0 and 1: %v", x)
+	}
+	return nil
+}
+
+func (p *PAF) SetArgProbability(arg Arg, x float64) error {
+	if err := checkProbability(x); err != nil {
+		return err
+	}
+	p.argProbs[arg] = x
+	return nil
+}
+
+func (p *PAF) SetAttackProbability(from, to Arg, x float64) error {
+	if err := checkProbability(x); err != nil {
+		return err
+	}
+	p.atkProbs[attack{from, to}] = x
+	return nil
+}
+
+// Returns the probability of the argument, 1 by default
+func (p *PAF) ArgProbability(arg Arg) float64 {
+	if x, ok := p.argProbs[arg]; ok {
+		return x
+	}
+	return 1
+}
+
+// Returns the probability of the attack, given that its arguments are
+// present, 1 by default
+func (p *PAF) AttackProbability(from, to Arg) float64 {
+	if x, ok := p.atkProbs[attack{from, to}]; ok {
+		return x
+	}
+	return 1
+}
+
+// Returns the arguments of the AF on which the acceptance of arg depends:
+// all arguments, or, for semantics satisfying directionality, the
+// arguments from which arg can be reached via attacks.
+func (p *PAF) relevant(s Semantics, arg Arg) []Arg {
+	switch s {
+	case Grounded, Complete, Preferred:
+	default:
+		return p.af.args
+	}
+	reached := NewArgSet(arg)
+	queue := []Arg{arg}
+	for len(queue) > 0 {
+		a := queue[0]
+		queue = queue[1:]
+		for _, atk := range p.af.atks[a] {
+			if !reached.Contains(atk) {
+				reached[atk] = true
+				queue = append(queue, atk)
+			}
+		}
+	}
+	args := []Arg{}
+	for _, a := range p.af.args {
+		if reached.Contains(a) {
+			args = append(args, a)
+		}
+	}
+	return args
+}
+
+// Returns the attacks between the present arguments. Attacks by
+// arguments which are not arguments of the AF are included, as in the
+// AF.
+func (p *PAF) attacksOf(present ArgSet) []attack {
+	declared := NewArgSet(p.af.args...)
+	attacks := []attack{}
+	for _, arg := range p.af.args {
+		if !present.Contains(arg) {
+			continue
+		}
+		for _, atk := range p.af.atks[arg] {
+			if present.Contains(atk) || !declared.Contains(atk) {
+				attacks = append(attacks, attack{atk, arg})
+			}
+		}
+	}
+	return attacks
+}
+
+// Returns true if the argument is accepted in the AF induced by the
+// present arguments and attacks
+func (p *PAF) accepted(s Semantics, arg Arg, skeptical bool, present ArgSet, attacks []attack) bool {
+	if !present.Contains(arg) {
+		return false
+	}
+	args := []Arg{}
+	for _, a := range p.af.args {
+		if present.Contains(a) {
+			args = append(args, a)
+		}
+	}
+	atks := make(map[Arg][]Arg)
+	for _, a := range attacks {
+		atks[a.to] = append(atks[a.to], a.from)
+	}
+	af := NewAF(args, atks)
+	af.solver = p.af.solver
+	switch {
+	case s == Grounded:
+		return af.GroundedExtension().Contains(arg)
+	case skeptical:
+		return af.SkepticallyInferred(s, arg)
+	default:
+		return af.CredulouslyInferred(s, arg)
+	}
+}
+
+// Returns the probability that the argument is credulously, or, if
+// skeptical is true, skeptically accepted using the given semantics. The
+// probability is computed exactly, by enumerating the AFs induced by the
+// arguments and attacks with probabilities other than 0 and 1, so the
+// cost is exponential in the number of these arguments and attacks. For
+// grounded, complete and preferred semantics, only the arguments from
+// which the argument can be reached via attacks are taken into account.
+func (p *PAF) ExactProbability(s Semantics, arg Arg, skeptical bool) float64 {
+	relevant := p.relevant(s, arg)
+	present := NewArgSet()
+	uncertain := []Arg{}
+	for _, a := range relevant {
+		switch x := p.ArgProbability(a); {
+		case x == 1:
+			present[a] = true
+		case x > 0:
+			uncertain = append(uncertain, a)
+		}
+	}
+	total := 0.0
+	// choose the present attacks, given the present arguments
+	chooseAttacks := func(prob float64) {
+		certain := []attack{}
+		uncertainAtks := []attack{}
+		for _, a := range p.attacksOf(present) {
+			switch x := p.AttackProbability(a.from, a.to); {
+			case x == 1:
+				certain = append(certain, a)
+			case x > 0:
+				uncertainAtks = append(uncertainAtks, a)
+			}
+		}
+		var choose func(i int, attacks []attack, prob float64)
+		choose = func(i int, attacks []attack, prob float64) {
+			if i == len(uncertainAtks) {
+				if p.accepted(s, arg, skeptical, present, attacks) {
+					total += prob
+				}
+				return
+			}
+			a := uncertainAtks[i]
+			x := p.AttackProbability(a.from, a.to)
+			choose(i+1, attacks, prob*(1-x))
+			choose(i+1, append(attacks[:len(attacks):len(attacks)], a), prob*x)
+		}
+		choose(0, certain, prob)
+	}
+	// choose the present arguments
+	var choose func(i int, prob float64)
+	choose = func(i int, prob float64) {
+		if i == len(uncertain) {
+			chooseAttacks(prob)
+			return
+		}
+		a := uncertain[i]
+		x := p.ArgProbability(a)
+		choose(i+1, prob*(1-x))
+		present[a] = true
+		choose(i+1, prob*x)
+		delete(present, a)
+	}
+	choose(0, 1)
+	return total
+}
+
+// Returns an estimate of the probability that the argument is
+// credulously, or skeptically, accepted using the given semantics, as
+// in ExactProbability, computed by sampling the given number of induced
+// AFs, using the random number generator r. Using a generator with a
+// fixed seed, the estimate can be reproduced. The standard error of the
+// estimate is at most 0.5/sqrt(samples).
+func (p *PAF) SampledProbability(r *rand.Rand, samples int, s Semantics, arg Arg, skeptical bool) float64 {
+	if samples <= 0 {
+		return 0
+	}
+	relevant := p.relevant(s, arg)
+	accepted := 0
+	for i := 0; i < samples; i++ {
+		present := NewArgSet()
+		for _, a := range relevant {
+			if r.Float64() < p.ArgProbability(a) {
+				present[a] = true
+			}
+		}
+		attacks := []attack{}
+		for _, a := range p.attacksOf(present) {
+			if r.Float64() < p.AttackProbability(a.from, a.to) {
+				attacks = append(attacks, a)
+			}
+		}
+		if p.accepted(s, arg, skeptical, present, attacks) {
+			accepted++
+		}
+	}
+	return float64(accepted) / float64(samples)
+}
@@ -0,0 +1,303 @@
+// Copyright © 2015 The Carneades Authors
+// This Source Code Form is subject to the terms of the
+// Mozilla Public License, v. 2.0. If a copy of the MPL
+// was not distributed with this file, You can obtain one
+// at http://mozilla.org/MPL/2.0/.
+
+// Decomposition of Dung AFs into strongly connected components (SCCs),
+// SCC-recursive semantics and component-wise evaluation.
+
+package dung
+
+// Returns the strongly connected components of the graph, using Tarjan's
+// algorithm. The components are ordered topologically: every attacker of
+// a node is in the same or some earlier component.
+func (g *graph) sccs() [][]int {
+	index := make([]int, len(g.args)) // 0 means not yet visited
+	lowlink := make([]int, len(g.args))
+	onStack := make([]bool, len(g.args))
+	stack := []int{}
+	counter := 0
+	components := [][]int{}
+
+	var connect func(v int)
+	connect = func(v int) {
+		counter++
+		index[v] = counter
+		lowlink[v] = counter
+		stack = append(stack, v)
+		onStack[v] = true
+		for _, w := range g.targets[v] {
+			if index[w] == 0 {
+				connect(w)
+				if lowlink[w] < lowlink[v] {
+					lowlink[v] = lowlink[w]
+				}
+			} else if onStack[w] && index[w] < lowlink[v] {
+				lowlink[v] = index[w]
+			}
+		}
+		if lowlink[v] == index[v] {
+			component := []int{}
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				component = append(component, w)
+				if w == v {
+					break
+				}
+			}
+			components = append(components, component)
+		}
+	}
+
+	for v := range g.args {
+		if index[v] == 0 {
+			connect(v)
+		}
+	}
+
+	// Tarjan's algorithm finds the components in reverse topological order
+	for i, j := 0, len(components)-1; i < j; i, j = i+1, j-1 {
+		components[i], components[j] = components[j], components[i]
+	}
+	return components
+}
+
+// Returns the strongly connected components of the AF, ordered
+// topologically, so that every attacker of an argument is a member
+// of the same or some earlier component.
+func (af *AF) SCCs() [][]Arg {
+	g := newGraph(af)
+	result := [][]Arg{}
+	for _, component := range g.sccs() {
+		args := []Arg{}
+		for _, i := range component {
+			if !g.external[i] {
+				args = append(args, g.args[i])
+			}
+		}
+		if len(args) > 0 {
+			result = append(result, args)
+		}
+	}
+	return result
+}
+
+// Returns the restriction of the AF to the given arguments, keeping
+// only the attacks between these arguments and the given external
+// attackers, which are not arguments of the AF and so never in.
+func (af *AF) restrict(S, external ArgSet) AF {
+	args := []Arg{}
+	for _, arg := range af.args {
+		if S.Contains(arg) {
+			args = append(args, arg)
+		}
+	}
+	nodes := append([]Arg{}, args...)
+	for arg := range external {
+		nodes = append(nodes, arg)
+	}
+	atks := make(map[Arg][]Arg)
+	for _, arg := range nodes {
+		for _, atk := range af.atks[arg] {
+			if S.Contains(atk) || external.Contains(atk) {
+				atks[arg] = append(atks[arg], atk)
+			}
+		}
+	}
+	return AF{args: args, atks: atks, solver: af.solver}
+}
+
+// Computes the extensions of an SCC-recursive semantics, such as CF2 or
+// stage2, given the semantics applied to AFs with a single SCC.
+//
+// E is an extension of the AF iff either the AF has a single SCC and E is
+// an extension of the base semantics, or, for every SCC S, E ∩ S is an
+// extension of the restriction of the AF to the members of S which are not
+// attacked by members of E outside of S. Attackers which are not
+// arguments of the AF are members of the SCCs too, as in the other
+// semantics, but never members of E.
+// See: Baroni, P., Giacomin, M. and Guida, G. SCC-recursiveness: a general
+// schema for argumentation semantics. Artificial Intelligence 168 (2005).
+func (af *AF) sccRecursive(base Semantics) []ArgSet {
+	g := newGraph(af)
+	sccs := g.sccs()
+	if len(sccs) <= 1 {
+		return af.Extensions(base)
+	}
+	extensions := []ArgSet{}
+	var next func(k int, E ArgSet)
+	next = func(k int, E ArgSet) {
+		if k == len(sccs) {
+			extensions = append(extensions, E)
+			return
+		}
+		S, external := NewArgSet(), NewArgSet()
+		for _, i := range sccs[k] {
+			if g.external[i] {
+				external[g.args[i]] = true
+			} else {
+				S[g.args[i]] = true
+			}
+		}
+		// Only members of earlier components can attack members of S
+		// from outside of S.
+		up := NewArgSet()
+		for arg := range S {
+			attacked := false
+			for _, atk := range af.atks[arg] {
+				if !S.Contains(atk) && E.Contains(atk) {
+					attacked = true
+					break
+				}
+			}
+			if !attacked {
+				up[arg] = true
+			}
+		}
+		sub := af.restrict(up, external)
+		for _, E2 := range sub.sccRecursive(base) {
+			E3 := E.Copy()
+			for arg := range E2 {
+				E3[arg] = true
+			}
+			next(k+1, E3)
+		}
+	}
+	next(0, NewArgSet())
+	return extensions
+}
+
+// Constructs a graph for a component, given the labels of the nodes
+// in earlier components. Attacks from earlier components are replaced by
+// attacks from a node which is in, if the attacker is in, and by attacks
+// from an external node, which is undecided, if the attacker is undecided.
+// Attacks from attackers which are out are ignored. Also returns the
+// nodes of the component, such that the i-th node of the component graph
+// is the node nodes[i] of g.
+func (g *graph) component(members []int, labels []uint8) (*graph, []int) {
+	c := &graph{index: make(map[Arg]int), done: g.done}
+	nodes := []int{}
+	local := make(map[int]int)
+	addNode := func(arg Arg, external bool) int {
+		i := len(c.args)
+		c.args = append(c.args, arg)
+		c.external = append(c.external, external)
+		c.attackers = append(c.attackers, []int{})
+		c.targets = append(c.targets, []int{})
+		return i
+	}
+	add := func(arg Arg, external bool) int {
+		i := addNode(arg, external)
+		c.index[arg] = i
+		return i
+	}
+	// the arguments of the AF first, followed by external nodes
+	for _, external := range []bool{false, true} {
+		for _, i := range members {
+			if g.external[i] == external {
+				local[i] = add(g.args[i], external)
+				nodes = append(nodes, i)
+			}
+		}
+		if !external {
+			c.n = len(c.args)
+		}
+	}
+	inNode, undecNode := -1, -1
+	attack := func(from, to int) {
+		c.attackers[to] = append(c.attackers[to], from)
+		c.targets[from] = append(c.targets[from], to)
+	}
+	for _, i := range members {
+		for _, b := range g.attackers[i] {
+			if j, found := local[b]; found {
+				attack(j, local[i])
+				continue
+			}
+			switch labels[b] {
+			case dOut:
+				continue
+			case dIn:
+				if inNode < 0 {
+					inNode = addNode(Arg(""), false)
+				}
+				attack(inNode, local[i])
+			default:
+				if undecNode < 0 {
+					undecNode = addNode(Arg(""), true)
+				}
+				attack(undecNode, local[i])
+			}
+		}
+	}
+	return c, nodes
+}
+
+// Enumerate labellings of the graph, component by component along the
+// SCC order, calling visit with the labels of all nodes. For each component,
+// local enumerates the labellings of the component, given the labels of the
+// nodes in earlier components. Only suitable for complete and stable
+// labellings, since attackers which are in are represented by unattacked
+// nodes of the component graphs. Stops when visit returns true.
+func (g *graph) decomposed(local func(c *graph, visit func(*search) bool), visit func(labels []uint8) bool) {
+	sccs := g.sccs()
+	labels := make([]uint8, len(g.args))
+	var next func(k int) bool
+	next = func(k int) bool {
+		if k == len(sccs) {
+			return visit(labels)
+		}
+		c, nodes := g.component(sccs[k], labels)
+		stop := false
+		local(c, func(s *search) bool {
+			for j, i := range nodes {
+				labels[i] = s.dom[j]
+			}
+			stop = next(k + 1)
+			return stop
+		})
+		return stop
+	}
+	next(0)
+}
+
+// Enumerate the extensions of the AF with the given semantics, which
+// must be grounded, complete, preferred or stable, component by
+// component, calling f for each. Stops when f returns true.
+func (g *graph) decomposedExtensions(s Semantics, f func(ArgSet) bool) {
+	var local func(c *graph, visit func(*search) bool)
+	switch s {
+	case Grounded:
+		local = func(c *graph, visit func(*search) bool) {
+			s := newSearch(c, completeMode)
+			s.propagate()
+			visit(s)
+		}
+	case Complete:
+		local = func(c *graph, visit func(*search) bool) {
+			c.labellings(completeMode, visit)
+		}
+	case Preferred:
+		local = func(c *graph, visit func(*search) bool) {
+			c.maximal(completeMode, dIn, visit)
+		}
+	case Stable:
+		local = func(c *graph, visit func(*search) bool) {
+			c.labellings(stableMode, visit)
+		}
+	default:
+		return
+	}
+	g.decomposed(local, func(labels []uint8) bool {
+		E := NewArgSet()
+		for i := 0; i < g.n; i++ {
+			if labels[i] == dIn {
+				E[g.args[i]] = true
+			}
+		}
+		return f(E)
+	})
+}
@@ -0,0 +1,285 @@
+// Copyright © 2015 The Carneades Authors
+// This Source Code Form is subject to the terms of the
+// Mozilla Public License, v. 2.0. If a copy of the MPL
+// was not distributed with this file, You can obtain one
+// at http://mozilla.org/MPL/2.0/.
+
+// Argumentation frameworks with collective attacks (SETAFs), in which
+// sets of arguments attack arguments.
+// See: Nielsen, S. H. and Parsons, S. A generalization of Dung's abstract
+// framework for argumentation: Arguing with sets of attacking arguments.
+// Argumentation in Multi-Agent Systems (2007).
+
+package dung
+
+type SETAF struct {
+	args []Arg
+	atks map[Arg][][]Arg // arg to the sets of arguments attacking it
+}
+
+func NewSETAF(args []Arg, atks map[Arg][][]Arg) SETAF {
+	return SETAF{args: args, atks: atks}
+}
+
+func (f *SETAF) Args() []Arg {
+	return f.args
+}
+
+func (f *SETAF) Atks() map[Arg][][]Arg {
+	return f.atks
+}
+
+// Returns the attacking sets of the argument which are minimal, i.e.
+// which are not proper supersets of other attacking sets of the argument,
+// without duplicates. Non-minimal attacking sets do not affect the
+// semantics.
+func (f *SETAF) minimalAttacks(arg Arg) []ArgSet {
+	sets := []ArgSet{}
+	for _, T := range f.atks[arg] {
+		sets = append(sets, NewArgSet(T...))
+	}
+	minimal := []ArgSet{}
+	for i, S := range sets {
+		isMinimal := true
+		for j, T := range sets {
+			if i != j && subset(T, S) && (T.Size() < S.Size() || j < i) {
+				isMinimal = false
+				break
+			}
+		}
+		if isMinimal {
+			minimal = append(minimal, S)
+		}
+	}
+	return minimal
+}
+
+func subset(S1, S2 ArgSet) bool {
+	for arg := range S1 {
+		if !S2.Contains(arg) {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the equivalent AF, with the same extensions under every
+// semantics, if every minimal attacking set consists of a single
+// argument. The boolean value returned is false, and the AF undefined,
+// if the SETAF has some essentially collective attack.
+func (f *SETAF) AF() (AF, bool) {
+	atks := make(map[Arg][]Arg)
+	for _, arg := range f.args {
+		for _, T := range f.minimalAttacks(arg) {
+			if T.Size() != 1 {
+				return AF{}, false
+			}
+			for atk := range T {
+				atks[arg] = append(atks[arg], atk)
+			}
+		}
+	}
+	return NewAF(f.args, atks), true
+}
+
+// The attack relation of a SETAF, with the minimal attacking sets of
+// each argument.
+type setafGraph struct {
+	args      []Arg
+	attackers map[Arg][]ArgSet
+}
+
+func (f *SETAF) graph() *setafGraph {
+	g := &setafGraph{args: f.args, attackers: make(map[Arg][]ArgSet)}
+	for _, arg := range f.args {
+		g.attackers[arg] = f.minimalAttacks(arg)
+	}
+	return g
+}
+
+// Returns true if S attacks the argument, i.e. includes some attacking
+// set of the argument
+func (g *setafGraph) attacks(S ArgSet, arg Arg) bool {
+	for _, T := range g.attackers[arg] {
+		if subset(T, S) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns true if S defends the argument, i.e. attacks some member of
+// every attacking set of the argument
+func (g *setafGraph) defends(S ArgSet, arg Arg) bool {
+	for _, T := range g.attackers[arg] {
+		attacked := false
+		for b := range T {
+			if g.attacks(S, b) {
+				attacked = true
+				break
+			}
+		}
+		if !attacked {
+			return false
+		}
+	}
+	return true
+}
+
+func (g *setafGraph) conflictFree(S ArgSet) bool {
+	for arg := range S {
+		if g.attacks(S, arg) {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the arguments defended by S
+func (g *setafGraph) defended(S ArgSet) ArgSet {
+	D := NewArgSet()
+	for _, arg := range g.args {
+		if g.defends(S, arg) {
+			D[arg] = true
+		}
+	}
+	return D
+}
+
+// The grounded extension is the least fixpoint of the characteristic
+// function, mapping each set to the arguments it defends.
+func (g *setafGraph) grounded() ArgSet {
+	S := NewArgSet()
+	for {
+		D := g.defended(S)
+		if D.Equals(S) {
+			return S
+		}
+		S = D
+	}
+}
+
+// Enumerate the complete extensions, calling f for each. Stops when f
+// returns true. The search decides, for one argument after another,
+// whether it is in the extension, pruning sets which are not conflict
+// free, starting from the grounded extension, which is included in every
+// complete extension.
+func (g *setafGraph) complete(f func(ArgSet) bool) {
+	G := g.grounded()
+	undecided := []Arg{}
+	for _, arg := range g.args {
+		if !G.Contains(arg) && !g.attacks(G, arg) {
+			undecided = append(undecided, arg)
+		}
+	}
+	S := G.Copy()
+	var next func(k int) bool
+	next = func(k int) bool {
+		if k == len(undecided) {
+			// S is conflict free. It is complete if it is a fixpoint
+			// of the characteristic function.
+			if g.defended(S).Equals(S) {
+				return f(S.Copy())
+			}
+			return false
+		}
+		arg := undecided[k]
+		S[arg] = true
+		if g.conflictFree(S) && next(k+1) {
+			return true
+		}
+		delete(S, arg)
+		return next(k + 1)
+	}
+	next(0)
+}
+
+// Returns the extensions of the SETAF using the given semantics, which
+// must be grounded, complete, preferred or stable. Returns nil for other
+// semantics.
+func (f *SETAF) Extensions(s Semantics) []ArgSet {
+	g := f.graph()
+	switch s {
+	case Grounded:
+		return []ArgSet{g.grounded()}
+	case Complete:
+		l := []ArgSet{}
+		g.complete(func(E ArgSet) bool {
+			l = append(l, E)
+			return false
+		})
+		return l
+	case Preferred:
+		// the maximal complete extensions
+		l := []ArgSet{}
+		g.complete(func(E ArgSet) bool {
+			l = append(l, E)
+			return false
+		})
+		preferred := []ArgSet{}
+		for i, E1 := range l {
+			maximal := true
+			for j, E2 := range l {
+				if i != j && E1.Size() < E2.Size() && subset(E1, E2) {
+					maximal = false
+					break
+				}
+			}
+			if maximal {
+				preferred = append(preferred, E1)
+			}
+		}
+		return preferred
+	case Stable:
+		// the complete extensions attacking every other argument
+		l := []ArgSet{}
+		g.complete(func(E ArgSet) bool {
+			for _, arg := range f.args {
+				if !E.Contains(arg) && !g.attacks(E, arg) {
+					return false
+				}
+			}
+			l = append(l, E)
+			return false
+		})
+		return l
+	default:
+		return nil
+	}
+}
+
+func (f *SETAF) GroundedExtension() ArgSet {
+	return f.graph().grounded()
+}
+
+func (f *SETAF) CompleteExtensions() []ArgSet {
+	return f.Extensions(Complete)
+}
+
+func (f *SETAF) PreferredExtensions() []ArgSet {
+	return f.Extensions(Preferred)
+}
+
+func (f *SETAF) StableExtensions() []ArgSet {
+	return f.Extensions(Stable)
+}
+
+// Returns true if the argument is a member of some extension
+func (f *SETAF) CredulouslyInferred(s Semantics, arg Arg) bool {
+	for _, E := range f.Extensions(s) {
+		if E.Contains(arg) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns true if the argument is a member of every extension
+func (f *SETAF) SkepticallyInferred(s Semantics, arg Arg) bool {
+	for _, E := range f.Extensions(s) {
+		if !E.Contains(arg) {
+			return false
+		}
+	}
+	return true
+}
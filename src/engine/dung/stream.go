@@ -0,0 +1,82 @@
+// Copyright © 2015 The Carneades Authors
+// This Source Code Form is subject to the terms of the
+// Mozilla Public License, v. 2.0. If a copy of the MPL
+// was not distributed with this file, You can obtain one
+// at http://mozilla.org/MPL/2.0/.
+
+// Streaming, cancellable enumeration of extensions.
+
+package dung
+
+import "context"
+
+// Enumerates the extensions of the AF with the given semantics, calling
+// f for each extension as soon as it has been found. The enumeration
+// stops when f returns true, when limit extensions have been enumerated,
+// if limit is positive, or when ctx is done. Returns ctx.Err() if ctx is
+// done when the enumeration stops, and nil otherwise.
+//
+// The searches of the backtracking and SCC solvers are cancelled as soon
+// as ctx is done. The extensions of the ideal, eager, CF2 and stage2
+// semantics, and all extensions computed by the subset solver, are
+// computed before the first extension is passed to f, and the computation
+// is not interrupted when ctx is done. See Cancellable.
+func (af *AF) EnumerateExtensions(ctx context.Context, s Semantics, limit int, f func(ArgSet) bool) error {
+	count := 0
+	visit := func(E ArgSet) bool {
+		if ctx.Err() != nil {
+			return true
+		}
+		count++
+		return f(E) || limit > 0 && count >= limit
+	}
+	if af.solver == SubsetSolver {
+		for _, E := range af.subsetExtensions(s) {
+			if visit(E) {
+				break
+			}
+		}
+	} else {
+		af.backtrackingEnumerate(ctx.Done(), s, visit)
+	}
+	return ctx.Err()
+}
+
+// Reports whether EnumerateExtensions stops computing the extensions of
+// the AF with the given semantics as soon as its context is done. The
+// grounded extension is computed in polynomial time.
+func (af *AF) Cancellable(s Semantics) bool {
+	if af.solver == SubsetSolver {
+		return false
+	}
+	switch s {
+	case Ideal, Eager, CF2, Stage2:
+		return false
+	default:
+		return true
+	}
+}
+
+// Streams the extensions of the AF with the given semantics, as they are
+// found, through the channel returned, which is closed when the
+// enumeration stops. See EnumerateExtensions. The extensions are
+// enumerated in a separate goroutine. Receivers which stop receiving
+// before the channel has been closed should cancel ctx, so that the
+// goroutine terminates. Whether the enumeration was cancelled before it
+// was complete can be checked with ctx.Err(), after the channel has been
+// closed.
+func (af *AF) StreamExtensions(ctx context.Context, s Semantics, limit int) <-chan ArgSet {
+	ch := make(chan ArgSet)
+	go func() {
+		defer close(ch)
+		af.EnumerateExtensions(ctx, s, limit, func(E ArgSet) bool {
+			select {
+			case ch <- E:
+				return false
+			case <-ctx.Done():
+				return true
+			}
+		})
+	}()
+	return ch
+}
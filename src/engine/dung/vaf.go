@@ -0,0 +1,222 @@
+// Copyright © 2015 The Carneades Authors
+// This Source Code Form is subject to the terms of the
+// Mozilla Public License, v. 2.0. If a copy of the MPL
+// was not distributed with this file, You can obtain one
+// at http://mozilla.org/MPL/2.0/.
+
+// Value-based argumentation frameworks (VAFs), in which each argument
+// promotes a value and audiences order the values.
+// See: Bench-Capon, T. Persuasion in practical argument using value-based
+// argumentation frameworks. Journal of Logic and Computation 13 (2003).
+
+package dung
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type Value string
+
+type VAF struct {
+	args   []Arg
+	atks   map[Arg][]Arg // arg to attackers
+	values map[Arg]Value // the value promoted by each argument
+}
+
+func NewVAF(args []Arg, atks map[Arg][]Arg, values map[Arg]Value) VAF {
+	return VAF{args: args, atks: atks, values: values}
+}
+
+func (vaf *VAF) Args() []Arg {
+	return vaf.args
+}
+
+func (vaf *VAF) Atks() map[Arg][]Arg {
+	return vaf.atks
+}
+
+func (vaf *VAF) Value(arg Arg) Value {
+	return vaf.values[arg]
+}
+
+// Returns the values promoted by the arguments, without duplicates, in
+// the order of the arguments.
+func (vaf *VAF) Values() []Value {
+	values := []Value{}
+	seen := make(map[Value]bool)
+	for _, arg := range vaf.args {
+		v, found := vaf.values[arg]
+		if found && !seen[v] {
+			seen[v] = true
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// An audience is a strict partial order on values. Values not ordered
+// by the audience are not preferred to any other value.
+type Audience struct {
+	order   []Value                  // the values, most preferred first, if the order is total
+	greater map[Value]map[Value]bool // v1 to the values v2 such that v1 > v2
+}
+
+// Constructs an audience which totally orders the given values, with
+// the most preferred value first.
+func NewTotalAudience(values ...Value) Audience {
+	a := Audience{order: values, greater: make(map[Value]map[Value]bool)}
+	for i, v1 := range values {
+		a.greater[v1] = make(map[Value]bool)
+		for _, v2 := range values[i+1:] {
+			a.greater[v1][v2] = true
+		}
+	}
+	return a
+}
+
+// Constructs an audience from a preference relation mapping values to
+// less preferred values. The audience orders the values by the transitive
+// closure of the relation, which must be acyclic.
+func NewAudience(prefs map[Value][]Value) (Audience, error) {
+	a := Audience{greater: make(map[Value]map[Value]bool)}
+	var visit func(v1, v2 Value)
+	visit = func(v1, v2 Value) {
+		for _, v3 := range prefs[v2] {
+			if !a.greater[v1][v3] {
+				a.greater[v1][v3] = true
+				visit(v1, v3)
+			}
+		}
+	}
+	for v := range prefs {
+		a.greater[v] = make(map[Value]bool)
+		visit(v, v)
+		if a.greater[v][v] {
+			return Audience{}, fmt.Errorf("cyclic preferences for value %s", v)
+		}
+	}
+	return a, nil
+}
+
+// Returns true if the audience prefers v1 to v2
+func (a Audience) Prefers(v1, v2 Value) bool {
+	return a.greater[v1][v2]
+}
+
+func (a Audience) String() string {
+	if a.order != nil {
+		s := []string{}
+		for _, v := range a.order {
+			s = append(s, string(v))
+		}
+		return strings.Join(s, " > ")
+	}
+	s := []string{}
+	for v1, vs := range a.greater {
+		for v2 := range vs {
+			s = append(s, fmt.Sprintf("%s > %s", v1, v2))
+		}
+	}
+	sort.Strings(s)
+	return "{" + strings.Join(s, ", ") + "}"
+}
+
+// Returns the AF of the defeats of the VAF for the audience. An attack of
+// a on b succeeds, and is a defeat, unless the audience prefers the value
+// of b to the value of a.
+func (vaf *VAF) AF(a Audience) AF {
+	atks := make(map[Arg][]Arg)
+	for _, b := range vaf.args {
+		for _, atk := range vaf.atks[b] {
+			if !a.Prefers(vaf.values[b], vaf.values[atk]) {
+				atks[b] = append(atks[b], atk)
+			}
+		}
+	}
+	return NewAF(vaf.args, atks)
+}
+
+// Returns true if the argument is accepted by the audience, i.e.
+// skeptically inferred from the AF of the defeats for the audience,
+// using the given semantics.
+func (vaf *VAF) Accepted(s Semantics, a Audience, arg Arg) bool {
+	af := vaf.AF(a)
+	return af.SkepticallyInferred(s, arg)
+}
+
+// Enumerate the audiences which totally order the values of the VAF,
+// calling f for each. Stops when f returns true.
+func (vaf *VAF) enumerateAudiences(f func(Audience) bool) {
+	values := vaf.Values()
+	order := make([]Value, 0, len(values))
+	used := make([]bool, len(values))
+	var next func() bool
+	next = func() bool {
+		if len(order) == len(values) {
+			return f(NewTotalAudience(append([]Value{}, order...)...))
+		}
+		for i, v := range values {
+			if used[i] {
+				continue
+			}
+			used[i] = true
+			order = append(order, v)
+			stop := next()
+			order = order[:len(order)-1]
+			used[i] = false
+			if stop {
+				return true
+			}
+		}
+		return false
+	}
+	next()
+}
+
+// Returns all audiences which totally order the values of the VAF.
+// The number of audiences is the factorial of the number of values.
+func (vaf *VAF) Audiences() []Audience {
+	audiences := []Audience{}
+	vaf.enumerateAudiences(func(a Audience) bool {
+		audiences = append(audiences, a)
+		return false
+	})
+	return audiences
+}
+
+// Returns the audiences, among the audiences which totally order the
+// values of the VAF, which accept the argument using the given semantics.
+func (vaf *VAF) AcceptingAudiences(s Semantics, arg Arg) []Audience {
+	audiences := []Audience{}
+	vaf.enumerateAudiences(func(a Audience) bool {
+		if vaf.Accepted(s, a, arg) {
+			audiences = append(audiences, a)
+		}
+		return false
+	})
+	return audiences
+}
+
+// Returns true if the argument is objectively accepted, i.e. accepted
+// by every audience which totally orders the values of the VAF.
+func (vaf *VAF) ObjectivelyAccepted(s Semantics, arg Arg) bool {
+	accepted := true
+	vaf.enumerateAudiences(func(a Audience) bool {
+		accepted = vaf.Accepted(s, a, arg)
+		return !accepted
+	})
+	return accepted
+}
+
+// Returns true if the argument is subjectively accepted, i.e. accepted
+// by some audience which totally orders the values of the VAF.
+func (vaf *VAF) SubjectivelyAccepted(s Semantics, arg Arg) bool {
+	accepted := false
+	vaf.enumerateAudiences(func(a Audience) bool {
+		accepted = vaf.Accepted(s, a, arg)
+		return accepted
+	})
+	return accepted
+}
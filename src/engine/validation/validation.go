@@ -123,6 +123,20 @@ func validateIssues(ag *caes.ArgGraph) []Problem {
 				}
 			}
 		}
+		// check that the proof standard has been registered and
+		// has the parameters given
+		ps, ok := v1.Standard.Definition()
+		if !ok {
+			p := Problem{ISSUE, i1, "unknown proof standard", v1.Standard.Name}
+			problems = append(problems, p)
+			continue
+		}
+		for k := range v1.Standard.Parameters {
+			if _, ok := ps.Parameters[k]; !ok {
+				p := Problem{ISSUE, i1, "unknown parameter of the proof standard " + v1.Standard.Name, k}
+				problems = append(problems, p)
+			}
+		}
 	}
 	return problems
 }
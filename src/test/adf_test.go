@@ -0,0 +1,98 @@
+// Copyright © 2015 The Carneades Authors
+// This Source Code Form is subject to the terms of the
+// Mozilla Public License, v. 2.0. If a copy of the MPL
+// was not distributed with this file, You can obtain one
+// at http://mozilla.org/MPL/2.0/.
+
+package test
+
+import (
+	"fmt"
+	"github.com/carneades/carneades-4/src/engine/adf"
+	"github.com/carneades/carneades-4/src/engine/dung"
+	"math/rand"
+	"testing"
+)
+
+// Returns true if the labellings are the same, in any order
+func equalLabellings(l1, l2 []dung.Labelling) bool {
+	if len(l1) != len(l2) {
+		return false
+	}
+	for _, v1 := range l1 {
+		found := false
+		for _, v2 := range l2 {
+			found = found || v1.Equals(v2)
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func TestADF(t *testing.T) {
+	// a is accepted, b requires a, c requires b or not a, and d and e
+	// support each other
+	f, err := adf.NewADF([]dung.Arg{"a", "b", "c", "d", "e"},
+		map[dung.Arg]string{
+			"b": "a",
+			"c": "b || !a",
+			"d": "e",
+			"e": "and(d, true)",
+		})
+	check(t, err)
+	expected := dung.Labelling{"a": dung.In, "b": dung.In, "c": dung.In, "d": dung.Undecided, "e": dung.Undecided}
+	if v := f.GroundedInterpretation(); !v.Equals(expected) {
+		t.Errorf("expected grounded interpretation %s, not %s", expected, v)
+	}
+	if n := len(f.CompleteInterpretations()); n != 3 {
+		t.Errorf("expected 3 complete interpretations, not %v", f.CompleteInterpretations())
+	}
+	models := []dung.Labelling{
+		{"a": dung.In, "b": dung.In, "c": dung.In, "d": dung.In, "e": dung.In},
+		{"a": dung.In, "b": dung.In, "c": dung.In, "d": dung.Out, "e": dung.Out},
+	}
+	if !equalLabellings(f.Models(), models) {
+		t.Errorf("expected the models %v, not %v", models, f.Models())
+	}
+	if !equalLabellings(f.PreferredInterpretations(), models) {
+		t.Errorf("expected the preferred interpretations %v, not %v", models, f.PreferredInterpretations())
+	}
+
+	// b requires a and not b, so b is out in every model, and then a,
+	// which requires exactly one of a and b, is out too
+	f, err = adf.NewADF([]dung.Arg{"a", "b"}, map[dung.Arg]string{"a": "a != b", "b": "imp(a, b) == false"})
+	check(t, err)
+	expected = dung.Labelling{"a": dung.Out, "b": dung.Out}
+	if len(f.Models()) != 1 || !f.Models()[0].Equals(expected) {
+		t.Errorf("expected the model %s, not %v", expected, f.Models())
+	}
+
+	// errors
+	for _, cond := range []string{"c", "a + b", "not(a, b)"} {
+		if _, err := adf.NewADF([]dung.Arg{"a", "b"}, map[dung.Arg]string{"a": cond}); err == nil {
+			t.Errorf("expected an error for the condition %s", cond)
+		}
+	}
+
+	// cross-check the ADFs of AFs with the AF semantics
+	r := rand.New(rand.NewSource(18))
+	for i := 0; i < 100; i++ {
+		af := randomAF(r, 1+r.Intn(7), 0.05+0.4*r.Float64())
+		f := adf.FromAF(af)
+		name := fmt.Sprintf("random AF %d: %s", i, af.String())
+		if v, l := f.GroundedInterpretation(), af.GroundedLabelling(); !v.Equals(l) {
+			t.Errorf("%s: expected the grounded interpretation %s, not %s", name, l, v)
+		}
+		if v, l := f.CompleteInterpretations(), af.Labellings(dung.Complete); !equalLabellings(v, l) {
+			t.Errorf("%s: expected the complete interpretations %v, not %v", name, l, v)
+		}
+		if v, l := f.PreferredInterpretations(), af.Labellings(dung.Preferred); !equalLabellings(v, l) {
+			t.Errorf("%s: expected the preferred interpretations %v, not %v", name, l, v)
+		}
+		if v, l := f.Models(), af.Labellings(dung.Stable); !equalLabellings(v, l) {
+			t.Errorf("%s: expected the models %v, not %v", name, l, v)
+		}
+	}
+}
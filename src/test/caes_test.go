@@ -7,12 +7,18 @@
 package test
 
 import (
-	// "github.com/carneades/carneades-4/src/engine/caes"
+	"github.com/carneades/carneades-4/src/engine/caes"
 	"github.com/carneades/carneades-4/src/engine/caes/encoding/yaml"
+	"github.com/carneades/carneades-4/src/engine/dung"
+	"github.com/carneades/carneades-4/src/engine/validation"
 	// "log"
+	"context"
 	"fmt"
+	"math/rand"
 	"os"
 	"path"
+	"sort"
+	"strings"
 	"testing"
 )
 
@@ -64,3 +70,390 @@ func TestCAES(t *testing.T) {
 		t.Errorf("TestCAES failed\n")
 	}
 }
+
+// Imports the argument graph of a YAML example file. Failing to open the
+// file is fatal, but errors importing it are returned.
+func loadExample(t *testing.T, file string) (*caes.ArgGraph, error) {
+	f, err := os.Open(examples + file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	return yaml.Import(f)
+}
+
+func TestDungTranslation(t *testing.T) {
+	// CAES graphs simulating Dung AFs
+	for file, expected := range map[string][]dung.Arg{
+		"dung-reinstatement.yml": {"a", "c"},
+		"dung-attack-cycle.yml":  {},
+	} {
+		ag, err := loadExample(t, file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		af := ag.ToAF()
+		if E := af.GroundedExtension(); !E.Equals(dung.NewArgSet(expected...)) {
+			t.Errorf("%s: expected the grounded extension %v, not %s of %s", file, expected, E, af.String())
+		}
+	}
+
+	// a rebuttal, via the positions of an issue
+	ag := caes.NewArgGraph()
+	i := caes.NewIssue()
+	i.Id = "i1"
+	for _, id := range []string{"p", "q"} {
+		s := caes.NewStatement()
+		s.Id = id
+		s.Issue = i
+		i.Positions = append(i.Positions, s)
+		ag.Statements[id] = s
+		a := caes.NewArgument()
+		a.Id = "a" + id
+		a.Conclusion = s
+		s.Args = append(s.Args, a)
+		ag.Arguments[a.Id] = a
+	}
+	ag.Issues[i.Id] = i
+	af := ag.ToAF()
+	if !dung.NewArgSet(af.Atks()["ap"]...).Equals(dung.NewArgSet("aq")) ||
+		!dung.NewArgSet(af.Atks()["aq"]...).Equals(dung.NewArgSet("ap")) {
+		t.Errorf("expected a symmetric attack, not %s", af.String())
+	}
+
+	// the grounded labelling of the graph of an AF corresponds to the
+	// grounded labelling of the AF
+	r := rand.New(rand.NewSource(20))
+	for i := 0; i < 50; i++ {
+		af := randomAF(r, 1+r.Intn(8), 0.05+0.4*r.Float64())
+		ag := caes.FromAF(af)
+		l1 := af.GroundedLabelling()
+		l2 := ag.GroundedLabelling()
+		for _, arg := range af.Args() {
+			s := ag.Statements[caes.AcceptabilityStatement(arg)]
+			if l1[arg].String() != l2[s].String() {
+				t.Errorf("random AF %d: expected %s to be %s, not %s, in the graph of %s", i, s.Id, l1[arg], l2[s], af.String())
+			}
+		}
+		// translating back, the grounded extension of the AF extended
+		// with the arguments for the attacks is unchanged
+		af2 := ag.ToAF()
+		E := af2.GroundedExtension()
+		for _, arg := range af.Args() {
+			if E.Contains(arg) != l1.AsExtension().Contains(arg) {
+				t.Errorf("random AF %d: expected the grounded extension %s, not %s", i, l1.AsExtension(), E)
+			}
+		}
+	}
+}
+
+func TestLabelExplanations(t *testing.T) {
+	explain := func(file string) (*caes.ArgGraph, caes.Labelling, caes.Explanations) {
+		ag, err := loadExample(t, file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		l, ex := ag.ExplainedGroundedLabelling()
+		return ag, l, ex
+	}
+	ag, l, ex := explain("tweety.yml")
+	for id, rule := range map[string]caes.Rule{
+		"bird":     caes.AssumedRule,
+		"¬app(a1)": caes.SupportedRule,
+		"penguin":  caes.UnsupportedRule,
+		"flies":    caes.UnsupportedRule,
+	} {
+		s := ag.Statements[id]
+		if e := ex[s]; e.Rule != rule || e.Label != l[s] {
+			t.Errorf("expected %s to be %s by the rule %s, not %s by the rule %s", id, l[s], rule, e.Label, e.Rule)
+		}
+	}
+	if e := ex[ag.Statements["flies"]]; e.ArgWeights[ag.Arguments["a1"]] != 0 ||
+		e.Undercutters[ag.Statements["¬app(a1)"]] != caes.In {
+		t.Errorf("expected a1 to weigh 0 and its undercutter to be in, not %v and %v", e.ArgWeights, e.Undercutters)
+	}
+
+	// an issue resolved by the preponderance of the evidence
+	ag, l, ex = explain("rebuttal.yml")
+	p, notp := ag.Statements["p"], ag.Statements["¬p"]
+	for _, s := range []*caes.Statement{p, notp} {
+		e := ex[s]
+		if e.Rule != caes.IssueResolvedRule || e.Winner != notp || e.Label != l[s] ||
+			e.Weights[p] != 0.1 || e.Weights[notp] != 0.5 {
+			t.Errorf("expected the issue to be resolved in favour of ¬p, with the weights 0.1 and 0.5, not %v", e)
+		}
+	}
+
+	// the explanations are consistent with the labelling of the examples
+	d, err := os.Open(examples)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := d.Readdir(0)
+	d.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, fi := range files {
+		if path.Ext(fi.Name()) != ".yml" {
+			continue
+		}
+		ag, err := loadExample(t, fi.Name())
+		if err != nil {
+			continue // reported by TestCAES
+		}
+		ag.Infer()
+		l, ex := ag.ExplainedGroundedLabelling()
+		for _, s := range ag.Statements {
+			e := ex[s]
+			if e == nil || e.Label != l[s] || (e.Rule == caes.NoRule) != (l[s] == caes.Undecided) {
+				t.Errorf("%s: the explanation of %s is inconsistent with its label %s", fi.Name(), s.Id, l[s])
+			}
+		}
+	}
+}
+
+func TestEvaluator(t *testing.T) {
+	d, err := os.Open(examples)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := d.Readdir(0)
+	d.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	load := func(file string) *caes.ArgGraph {
+		ag, err := loadExample(t, file)
+		if err != nil {
+			return nil // reported by TestCAES
+		}
+		ag.Infer()
+		return ag
+	}
+	// Compares the labelling of the evaluator with the labelling computed
+	// by the fixpoint of GroundedLabelling
+	compare := func(name string, ag *caes.ArgGraph, l caes.Labelling) {
+		expected := ag.GroundedLabelling()
+		for _, s := range ag.Statements {
+			if l[s] != expected[s] {
+				t.Errorf("%s: expected %s to be %s, not %s", name, s.Id, expected[s], l[s])
+			}
+		}
+	}
+	for _, fi := range files {
+		if path.Ext(fi.Name()) != ".yml" {
+			continue
+		}
+		ag := load(fi.Name())
+		if ag == nil {
+			continue
+		}
+		compare(fi.Name(), ag, caes.NewEvaluator(ag).GroundedLabelling())
+
+		// add the arguments and then the assumptions one at a time
+		ag = load(fi.Name())
+		ids := []string{}
+		for id, arg := range ag.Arguments {
+			ids = append(ids, id)
+			arg.Conclusion.Args = nil
+		}
+		sort.Strings(ids)
+		args := ag.Arguments
+		assumptions := ag.Assumptions
+		ag.Arguments = map[string]*caes.Argument{}
+		ag.Assumptions = []string{}
+		e := caes.NewEvaluator(ag)
+		for _, id := range ids {
+			e.AddArgument(args[id])
+			compare(fmt.Sprintf("%s, after adding %s", fi.Name(), id), ag, e.GroundedLabelling())
+		}
+		for _, a := range assumptions {
+			e.AddAssumption(a)
+			compare(fmt.Sprintf("%s, after assuming %s", fi.Name(), a), ag, e.GroundedLabelling())
+		}
+	}
+}
+
+func TestProofStandards(t *testing.T) {
+	// p and ¬p are supported by arguments weighing 0.5 and 0.1
+	ag := func(standard string) *caes.ArgGraph {
+		src := `
+statements:
+  e: The evidence.
+  p: P
+  ¬p: ¬P
+issues:
+  i1:
+    positions: [p, ¬p]
+    standard: ` + standard + `
+argument_schemes:
+  - id: strong
+    weight:
+      constant: 0.5
+  - id: weak
+    weight:
+      constant: 0.1
+arguments:
+  a1:
+    scheme: strong
+    conclusion: p
+    premises: [e]
+  a2:
+    scheme: weak
+    conclusion: ¬p
+    premises: [e]
+assumptions: [e]
+`
+		ag, err := yaml.Import(strings.NewReader(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ag
+	}
+
+	// unknown standards and parameters are reported by the validation
+	for _, std := range []string{"majority", "{cce: {gamma: 0.2}}"} {
+		if problems := validation.Validate(ag(std)); len(problems) != 1 || problems[0].Category != validation.ISSUE {
+			t.Errorf("expected a problem with the standard %s, not %v", std, problems)
+		}
+	}
+	for _, std := range []string{"{cce: {alpha}}", "{cce: {alpha: high}}", "[pe, cce]"} {
+		if _, err := yaml.Import(strings.NewReader("issues: {i1: {positions: [p, q], standard: " + std + "}}")); err == nil {
+			t.Errorf("expected an error for the standard %s", std)
+		}
+	}
+
+	// a user-defined standard with a threshold
+	caes.RegisterStandard("Threshold", &caes.ProofStandard{
+		Parameters: map[string]float64{"min": 0.3},
+		Greater: func(w1, w2 float64, p map[string]float64) bool {
+			return w1 > w2 && w1 >= p["min"]
+		},
+	})
+	for std, winner := range map[string]bool{
+		"threshold":                    true,
+		"{threshold: {min: 0.6}}":      false,
+		"{Threshold: {MIN: 0.5}}":      true,
+		"{cce: {alpha: 0.3}}":          true,
+		"{brd: {alpha: 0.3, beta: 0}}": false,
+	} {
+		ag := ag(std)
+		if problems := validation.Validate(ag); len(problems) > 0 {
+			t.Errorf("%s: unexpected problems %v", std, problems)
+		}
+		l := caes.NewEvaluator(ag).GroundedLabelling()
+		if p := ag.Statements["p"]; (l[p] == caes.In) != winner {
+			t.Errorf("%s: expected p to win %v, not %s", std, winner, l[p])
+		}
+	}
+
+	// standards are equal if they have the same name and parameters
+	for _, c := range []struct {
+		s1, s2 caes.Standard
+		equal  bool
+	}{
+		{caes.Standard{}, caes.PE, true},
+		{caes.NewStandard("CCE", nil), caes.CCE, true},
+		{caes.CCE, caes.BRD, false},
+		{caes.NewStandard("cce", map[string]float64{"alpha": 0.3}), caes.NewStandard("cce", map[string]float64{"alpha": 0.3}), true},
+		{caes.NewStandard("cce", map[string]float64{"alpha": 0.3}), caes.CCE, false},
+	} {
+		if c.s1.Equal(c.s2) != c.equal {
+			t.Errorf("expected %s and %s to be equal %v", c.s1, c.s2, c.equal)
+		}
+	}
+
+	// the zero value of a standard is the preponderance of the evidence
+	g := ag("cce")
+	for _, issue := range g.Issues {
+		issue.Standard = caes.Standard{}
+		if issue.Standard.String() != "PE" {
+			t.Errorf("expected the standard PE, not %s", issue.Standard)
+		}
+	}
+	if l := g.GroundedLabelling(); l[g.Statements["p"]] != caes.In {
+		t.Errorf("expected p to win with the zero standard, not %s", l[g.Statements["p"]])
+	}
+}
+
+func TestSensitivity(t *testing.T) {
+	for file, expected := range map[string]map[string][]string{
+		"tweety.yml":   {"flies": {"in: retract ill"}, "bird": {"out: retract bird"}},
+		"rebuttal.yml": {"¬p": {"out: assume p", "out: retract r"}, "p": {"in: retract r"}},
+	} {
+		ag, err := loadExample(t, file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assumptions := fmt.Sprint(ag.Assumptions)
+		for id, changes := range expected {
+			cs, err := ag.Sensitivity(id, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual := []string{}
+			for _, c := range cs {
+				actual = append(actual, c.String())
+			}
+			sort.Strings(actual)
+			sort.Strings(changes)
+			if fmt.Sprint(actual) != fmt.Sprint(changes) {
+				t.Errorf("%s: expected the changes %v for %s, not %v", file, changes, id, actual)
+			}
+		}
+		if fmt.Sprint(ag.Assumptions) != assumptions {
+			t.Errorf("%s: expected the assumptions %s to be restored, not %v", file, assumptions, ag.Assumptions)
+		}
+		if _, err := ag.Sensitivity("unknown", 2); err == nil {
+			t.Errorf("%s: expected an error for an unknown statement", file)
+		}
+	}
+}
+
+func TestAbduction(t *testing.T) {
+	type goal struct {
+		id    string
+		label caes.Label
+	}
+	for file, expected := range map[string]map[goal][]string{
+		"tweety.yml":   {{"flies", caes.In}: {"reject ill", "reject ¬app(a1)"}, {"bird", caes.In}: {""}},
+		"rebuttal.yml": {{"¬p", caes.Out}: {"accept p", "reject r"}, {"p", caes.In}: {"reject r"}},
+	} {
+		ag, err := loadExample(t, file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for g, sets := range expected {
+			as, err := ag.Abduce(g.id, g.label, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual := []string{}
+			for _, a := range as {
+				actual = append(actual, a.String())
+			}
+			sort.Strings(actual)
+			sort.Strings(sets)
+			if fmt.Sprint(actual) != fmt.Sprint(sets) {
+				t.Errorf("%s: expected the sets %q to make %s %s, not %q", file, sets, g.id, g.label, actual)
+			}
+		}
+		if _, err := ag.Abduce("unknown", caes.In, 2); err == nil {
+			t.Errorf("%s: expected an error for an unknown statement", file)
+		}
+		if _, err := ag.Abduce("p", caes.Undecided, 2); err == nil {
+			t.Errorf("%s: expected an error for an undecided goal", file)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		for g, sets := range expected {
+			if sets[0] == "" {
+				continue // no search
+			}
+			if _, err := ag.AbduceContext(ctx, g.id, g.label, 2); err != context.Canceled {
+				t.Errorf("%s: expected the search for %s to be cancelled, not %v", file, g.id, err)
+			}
+		}
+	}
+}
@@ -7,12 +7,22 @@
 package test
 
 import (
+	"bytes"
+	"context"
+	"fmt"
 	"github.com/carneades/carneades-4/src/engine/dung"
+	"github.com/carneades/carneades-4/src/engine/dung/encoding/apx"
+	"github.com/carneades/carneades-4/src/engine/dung/encoding/i23"
 	"github.com/carneades/carneades-4/src/engine/dung/encoding/tgf"
-	// "fmt"
+	"github.com/carneades/carneades-4/src/engine/dung/gen"
 	"log"
+	"math"
+	"math/rand"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 const dungDir = "../../examples/AFs/TGF/"
@@ -151,3 +161,1124 @@ func TestEvenCycle1PreferredLabelling(t *testing.T) {
 		t.Errorf("expected %s, not %s.\n", expected, actual)
 	}
 }
+
+// randomAF constructs an AF with n arguments, where each possible attack,
+// including self attacks, is included with probability p.
+func randomAF(r *rand.Rand, n int, p float64) dung.AF {
+	args := []dung.Arg{}
+	for i := 0; i < n; i++ {
+		args = append(args, dung.Arg(fmt.Sprintf("%d", i)))
+	}
+	atks := make(map[dung.Arg][]dung.Arg)
+	for _, a := range args {
+		for _, b := range args {
+			if r.Float64() < p {
+				atks[b] = append(atks[b], a)
+			}
+		}
+	}
+	return dung.NewAF(args, atks)
+}
+
+var allSemantics = []dung.Semantics{dung.Grounded, dung.Complete,
+	dung.Preferred, dung.Stable, dung.SemiStable, dung.Stage, dung.Ideal,
+	dung.Eager, dung.Naive, dung.Admissible, dung.CF2, dung.Stage2}
+
+// Compare the results of the backtracking solver with those of the
+// reference solver, which enumerates all subsets of the arguments.
+func crossCheckSolvers(t *testing.T, name string, af dung.AF) {
+	crossCheckSolver(t, name, af, dung.BacktrackingSolver)
+	crossCheckSolver(t, name, af, dung.SCCSolver)
+}
+
+func crossCheckSolver(t *testing.T, name string, af dung.AF, solver dung.Solver) {
+	ref := af
+	ref.SetSolver(dung.SubsetSolver)
+	af.SetSolver(solver)
+	name = name + " (" + solver.String() + ")"
+	for _, s := range allSemantics {
+		l1, l2 := ref.Extensions(s), af.Extensions(s)
+		if len(l1) != len(l2) || !dung.EqualArgSetSlices(l1, l2) {
+			t.Errorf("%s: %s extensions: expected %v, not %v\n", name, s, l1, l2)
+		}
+		for _, arg := range af.Args() {
+			if ref.CredulouslyInferred(s, arg) != af.CredulouslyInferred(s, arg) {
+				t.Errorf("%s: credulous inference of %s differs for %s semantics\n", name, arg, s)
+			}
+			if ref.SkepticallyInferred(s, arg) != af.SkepticallyInferred(s, arg) {
+				t.Errorf("%s: skeptical inference of %s differs for %s semantics\n", name, arg, s)
+			}
+		}
+		for _, arg := range af.Args() {
+			E, ok := af.CredulousWitness(s, arg)
+			if ok != ref.CredulouslyInferred(s, arg) || ok && (!E.Contains(arg) || !containsArgSet(l1, E)) {
+				t.Errorf("%s: %v is not a %s witness for %s\n", name, E, s, arg)
+			}
+			E, ok = af.SkepticalCounterexample(s, arg)
+			if ok == ref.SkepticallyInferred(s, arg) || ok && (E.Contains(arg) || !containsArgSet(l1, E)) {
+				t.Errorf("%s: %v is not a %s counterexample for %s\n", name, E, s, arg)
+			}
+		}
+		_, ok1 := ref.SomeExtension(s)
+		_, ok2 := af.SomeExtension(s)
+		if ok1 != ok2 {
+			t.Errorf("%s: existence of an extension differs for %s semantics\n", name, s)
+		}
+		if n := af.CountExtensions(s); n != len(l1) {
+			t.Errorf("%s: expected %d %s extensions, not %d\n", name, len(l1), s, n)
+		}
+		// verify the extensions and the sets differing from the extensions
+		// by a single argument
+		for _, E := range l1 {
+			sets := []dung.ArgSet{E}
+			for _, arg := range af.Args() {
+				if E.Contains(arg) {
+					sets = append(sets, E.Remove(arg))
+				} else {
+					sets = append(sets, E.Add(arg))
+				}
+			}
+			for _, S := range sets {
+				if af.IsExtension(s, S) != containsArgSet(l1, S) {
+					t.Errorf("%s: verification of %v as a %s extension failed\n", name, S, s)
+				}
+			}
+		}
+	}
+}
+
+func containsArgSet(l []dung.ArgSet, E dung.ArgSet) bool {
+	for _, E2 := range l {
+		if E2.Equals(E) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestBacktrackingSolverExamples(t *testing.T) {
+	files, err := filepath.Glob(dungDir + "*.tgf")
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, file := range files {
+		inFile, err := os.Open(file)
+		if err != nil {
+			log.Fatal(err)
+		}
+		af, err := tgf.Import(inFile)
+		inFile.Close()
+		check(t, err)
+		crossCheckSolvers(t, file, af)
+	}
+}
+
+func TestBacktrackingSolverRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for i := 0; i < 200; i++ {
+		n := 1 + r.Intn(8)
+		p := 0.05 + 0.4*r.Float64()
+		crossCheckSolvers(t, fmt.Sprintf("random AF %d", i), randomAF(r, n, p))
+	}
+}
+
+func TestSemiStableWithoutStableExtension(t *testing.T) {
+	// 2 and 3 attack each other, 3 attacks 1, and 1 and 4 attack
+	// themselves. There is no stable extension, since nothing attacks 4.
+	// Both {2} and {3} are preferred, but only {3}, with range {1,2,3},
+	// is semi-stable.
+	a4 := dung.Arg("4")
+	args := []dung.Arg{a1, a2, a3, a4}
+	atks := make(map[dung.Arg][]dung.Arg)
+	atks[a1] = []dung.Arg{a1, a3}
+	atks[a2] = []dung.Arg{a3}
+	atks[a3] = []dung.Arg{a2}
+	atks[a4] = []dung.Arg{a4}
+	af := dung.NewAF(args, atks)
+	if _, ok := af.SomeExtension(dung.Stable); ok {
+		t.Errorf("expected no stable extension")
+	}
+	actual := af.SemiStableExtensions()
+	expected := []dung.ArgSet{dung.NewArgSet(a3)}
+	if len(actual) != 1 || !dung.EqualArgSetSlices(actual, expected) {
+		t.Errorf("expected %s, not %s.\n", expected, actual)
+	}
+}
+
+func TestIdealExtension(t *testing.T) {
+	// 1 and 2 attack each other and 2 attacks itself. The grounded
+	// extension is empty, but {1} is the ideal extension.
+	args := []dung.Arg{a1, a2}
+	atks := make(map[dung.Arg][]dung.Arg)
+	atks[a1] = []dung.Arg{a2}
+	atks[a2] = []dung.Arg{a1, a2}
+	af := dung.NewAF(args, atks)
+	expected := dung.NewArgSet(a1)
+	if actual := af.IdealExtension(); !actual.Equals(expected) {
+		t.Errorf("expected %s, not %s.\n", expected, actual)
+	}
+	if !af.SkepticallyInferred(dung.Ideal, a1) {
+		t.Errorf("expected 1 to be skeptically inferred")
+	}
+	if af.GroundedExtension().Contains(a1) {
+		t.Errorf("expected 1 not to be in the grounded extension")
+	}
+}
+
+func TestSCCs(t *testing.T) {
+	// 1 attacks 2, which attacks 3, which attacks 2
+	args := []dung.Arg{a3, a2, a1}
+	atks := make(map[dung.Arg][]dung.Arg)
+	atks[a2] = []dung.Arg{a1, a3}
+	atks[a3] = []dung.Arg{a2}
+	af := dung.NewAF(args, atks)
+	sccs := af.SCCs()
+	if len(sccs) != 2 {
+		t.Fatalf("expected 2 SCCs, not %v", sccs)
+	}
+	if !dung.NewArgSet(sccs[0]...).Equals(dung.NewArgSet(a1)) ||
+		!dung.NewArgSet(sccs[1]...).Equals(dung.NewArgSet(a2, a3)) {
+		t.Errorf("expected [[1] [2 3]], not %v", sccs)
+	}
+}
+
+func TestOddCycleCF2(t *testing.T) {
+	inFile, err := os.Open(dungDir + "odd_cycle1.tgf")
+	if err != nil {
+		log.Fatal(err)
+	}
+	af, err := tgf.Import(inFile)
+	check(t, err)
+	// Unlike preferred semantics, CF2 treats odd and even cycles alike,
+	// accepting each argument of a three-cycle in some extension.
+	if preferred := af.PreferredExtensions(); !dung.EqualArgSetSlices(preferred, []dung.ArgSet{dung.NewArgSet()}) {
+		t.Errorf("expected only the empty preferred extension, not %s", preferred)
+	}
+	actual := af.CF2Extensions()
+	expected := []dung.ArgSet{dung.NewArgSet(a1), dung.NewArgSet(a2), dung.NewArgSet(a3)}
+	if len(actual) != len(expected) || !dung.EqualArgSetSlices(actual, expected) {
+		t.Errorf("expected %s, not %s", expected, actual)
+	}
+}
+
+// The AF of the file af2.tgf, in which 1 attacks 2 and 2 attacks 3
+func checkAf2(t *testing.T, af dung.AF) {
+	expected := dung.NewArgSet(a1, a3)
+	if actual := af.GroundedExtension(); !actual.Equals(expected) {
+		t.Errorf("expected %s, not %s.\n", expected, actual)
+	}
+}
+
+func TestI23Import(t *testing.T) {
+	af, err := i23.Import(strings.NewReader("p af 3\n# comment\n1 2\n2 3\n"))
+	check(t, err)
+	if len(af.Args()) != 3 {
+		t.Errorf("expected 3 arguments, not %v", af.Args())
+	}
+	checkAf2(t, af)
+	// non-canonical indexes denote the same arguments
+	af, err = i23.Import(strings.NewReader("p af 3\n01 2\n+2 03\n"))
+	check(t, err)
+	if actual := fmt.Sprint(af.Atks()); actual != "map[2:[1] 3:[2]]" {
+		t.Errorf("expected the attacks map[2:[1] 3:[2]], not %s", actual)
+	}
+	for s, expected := range map[string]dung.Arg{"01": "1", "+2": "2", "a": "a"} {
+		if actual := i23.CanonicalArg(s); actual != expected {
+			t.Errorf("expected %s as the canonical argument of %s, not %s", expected, s, actual)
+		}
+	}
+	for _, s := range []string{"1 2\n", "p af 2\n1 3\n", "p af 2\n1\n"} {
+		if _, err := i23.Import(strings.NewReader(s)); err == nil {
+			t.Errorf("expected an error importing %q", s)
+		}
+	}
+}
+
+func TestApxImport(t *testing.T) {
+	af, err := apx.Import(strings.NewReader("% comment\narg(1). arg(2).\narg(3).\natt(1,2).\natt( 2 , 3 ).\n"))
+	check(t, err)
+	checkAf2(t, af)
+	for _, s := range []string{"arg(1)", "arg(1). att(1,2).", "foo(1)."} {
+		if _, err := apx.Import(strings.NewReader(s)); err == nil {
+			t.Errorf("expected an error importing %q", s)
+		}
+	}
+}
+
+func TestApxQuotedIdentifiers(t *testing.T) {
+	af, err := apx.Import(strings.NewReader(`arg("a b"). arg('c,d'). arg("e\"f"). att("a b",'c,d').`))
+	check(t, err)
+	expected := []dung.Arg{"a b", "c,d", `e"f`}
+	if actual := af.Args(); len(actual) != 3 || !dung.NewArgSet(actual...).Equals(dung.NewArgSet(expected...)) {
+		t.Errorf("expected %v, not %v", expected, actual)
+	}
+	if atks := af.Atks()["c,d"]; len(atks) != 1 || atks[0] != "a b" {
+		t.Errorf("expected c,d to be attacked by a b, not %v", atks)
+	}
+	if _, err := apx.Import(strings.NewReader(`arg("a).`)); err == nil {
+		t.Errorf("expected an error for an unterminated quoted identifier")
+	}
+}
+
+func TestApxExport(t *testing.T) {
+	files, err := filepath.Glob(dungDir + "*.tgf")
+	if err != nil {
+		log.Fatal(err)
+	}
+	files = append(files, "")
+	for _, file := range files {
+		var af dung.AF
+		if file == "" {
+			af = dung.NewAF([]dung.Arg{"a b", `"c"`, "007"},
+				map[dung.Arg][]dung.Arg{"007": {"a b", `"c"`}})
+		} else {
+			inFile, err := os.Open(file)
+			if err != nil {
+				log.Fatal(err)
+			}
+			af, err = tgf.Import(inFile)
+			inFile.Close()
+			check(t, err)
+		}
+		var buf bytes.Buffer
+		check(t, apx.Export(&buf, af))
+		af2, err := apx.Import(&buf)
+		check(t, err)
+		if !dung.NewArgSet(af.Args()...).Equals(dung.NewArgSet(af2.Args()...)) {
+			t.Errorf("%s: expected arguments %v, not %v", file, af.Args(), af2.Args())
+		}
+		for _, arg := range af.Args() {
+			if !dung.NewArgSet(af.Atks()[arg]...).Equals(dung.NewArgSet(af2.Atks()[arg]...)) {
+				t.Errorf("%s: expected attackers %v of %s, not %v", file, af.Atks()[arg], arg, af2.Atks()[arg])
+			}
+		}
+	}
+}
+
+// Check that every proponent node of the tree is countered only by
+// attackers of its argument, that every attacker of an expanded proponent
+// node is countered and that every opponent node is countered by one of its
+// attackers, which is a member of E.
+func checkDisputeTree(t *testing.T, name string, af dung.AF, tree *dung.DisputeTree, E dung.ArgSet) {
+	if tree.Player == dung.Proponent {
+		if !E.Contains(tree.Arg) {
+			t.Errorf("%s: proponent argument %s is not in %v", name, tree.Arg, E)
+		}
+		if tree.Repeated {
+			return
+		}
+		attackers := dung.NewArgSet(af.Atks()[tree.Arg]...)
+		countered := dung.NewArgSet()
+		for _, c := range tree.Children {
+			countered[c.Arg] = true
+		}
+		if !attackers.Equals(countered) || len(tree.Children) != attackers.Size() {
+			t.Errorf("%s: expected the attackers %v of %s to be countered, not %v", name, attackers, tree.Arg, countered)
+		}
+	} else if len(tree.Children) != 1 || !dung.NewArgSet(af.Atks()[tree.Arg]...).Contains(tree.Children[0].Arg) {
+		t.Errorf("%s: opponent argument %s is not countered", name, tree.Arg)
+	}
+	for _, c := range tree.Children {
+		checkDisputeTree(t, name, af, c, E)
+	}
+}
+
+func TestExplanations(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	for i := 0; i < 100; i++ {
+		af := randomAF(r, 1+r.Intn(8), 0.05+0.4*r.Float64())
+		name := fmt.Sprintf("random AF %d", i)
+		for _, s := range allSemantics {
+			for _, arg := range af.Args() {
+				e := af.ExplainCredulous(s, arg)
+				if e.Accepted != af.CredulouslyInferred(s, arg) {
+					t.Errorf("%s: wrong credulous answer for %s using %s semantics", name, arg, s)
+				}
+				switch s {
+				case dung.Grounded, dung.Complete, dung.Preferred, dung.Admissible:
+					if e.Accepted != (e.Tree != nil) {
+						t.Errorf("%s: expected a dispute tree for %s using %s semantics", name, arg, s)
+					}
+				}
+				if e.Tree != nil {
+					checkDisputeTree(t, name, af, e.Tree, dung.NewArgSet(e.Extension...))
+				}
+				e = af.ExplainSkeptical(s, arg)
+				if e.Accepted != af.SkepticallyInferred(s, arg) {
+					t.Errorf("%s: wrong skeptical answer for %s using %s semantics", name, arg, s)
+				}
+				if !e.Accepted && s != dung.Grounded && s != dung.Complete {
+					E := dung.NewArgSet(e.Extension...)
+					if E.Contains(arg) || !containsArgSet(af.Extensions(s), E) {
+						t.Errorf("%s: %v is not a %s counter-extension for %s", name, E, s, arg)
+					}
+				}
+			}
+		}
+	}
+}
+
+func checkAttacks(t *testing.T, name string, args []dung.Arg, actual, expected map[dung.Arg][]dung.Arg) {
+	for _, arg := range args {
+		if !dung.NewArgSet(actual[arg]...).Equals(dung.NewArgSet(expected[arg]...)) {
+			t.Errorf("%s: expected attackers %v of %s, not %v", name, expected[arg], arg, actual[arg])
+		}
+	}
+}
+
+func TestBipolarAF(t *testing.T) {
+	// a supports b, b attacks c, d supports c, e supports itself and
+	// attacks a
+	args := []dung.Arg{"a", "b", "c", "d", "e"}
+	atks := map[dung.Arg][]dung.Arg{"c": {"b"}, "a": {"e"}}
+	sups := map[dung.Arg][]dung.Arg{"b": {"a"}, "c": {"d"}, "e": {"e"}}
+	baf := dung.NewBAF(args, atks, sups)
+
+	checkAttacks(t, "supported attacks", args, baf.SupportedAttacks(),
+		map[dung.Arg][]dung.Arg{"c": {"a"}})
+	checkAttacks(t, "secondary attacks", args, baf.SecondaryAttacks(),
+		map[dung.Arg][]dung.Arg{"b": {"e"}})
+	checkAttacks(t, "mediated attacks", args, baf.MediatedAttacks(),
+		map[dung.Arg][]dung.Arg{"d": {"b"}})
+
+	af := baf.AF(dung.DeductiveSupport)
+	checkAttacks(t, "deductive support", args, af.Atks(),
+		map[dung.Arg][]dung.Arg{"a": {"e"}, "c": {"a", "b"}, "d": {"a", "b"}})
+	af = baf.AF(dung.NecessarySupport)
+	checkAttacks(t, "necessary support", args, af.Atks(),
+		map[dung.Arg][]dung.Arg{"a": {"e"}, "b": {"e"}, "c": {"b"}})
+	af = baf.AF(dung.EvidentialSupport)
+	if expected := dung.NewArgSet("a", "b", "c", "d"); !dung.NewArgSet(af.Args()...).Equals(expected) {
+		t.Errorf("expected the arguments %v, not %v", expected, af.Args())
+	}
+	if !af.GroundedExtension().Equals(dung.NewArgSet("a", "b", "d")) {
+		t.Errorf("expected a, b and d to be in, not %v", af.GroundedExtension())
+	}
+	// a supports b, c attacks a: b loses its only evidential support
+	ev := dung.NewBAF([]dung.Arg{"a", "b", "c"},
+		map[dung.Arg][]dung.Arg{"a": {"c"}},
+		map[dung.Arg][]dung.Arg{"b": {"a"}})
+	af = ev.AF(dung.EvidentialSupport)
+	if E := af.GroundedExtension(); !E.Equals(dung.NewArgSet("c")) {
+		t.Errorf("expected only c to be in, not %v", E)
+	}
+	// a and b support c, d attacks a: c remains supported by b
+	ev = dung.NewBAF([]dung.Arg{"a", "b", "c", "d"},
+		map[dung.Arg][]dung.Arg{"a": {"d"}},
+		map[dung.Arg][]dung.Arg{"c": {"a", "b"}})
+	af, metas := ev.EvidentialAF()
+	if expected := dung.NewArgSet("a", "b", "c/1", "c/2", "d"); !dung.NewArgSet(af.Args()...).Equals(expected) {
+		t.Errorf("expected the meta arguments %v, not %v", expected, af.Args())
+	}
+	E := dung.NewArgSet()
+	for m := range af.GroundedExtension() {
+		E[metas[m]] = true
+	}
+	if !E.Equals(dung.NewArgSet("b", "c", "d")) {
+		t.Errorf("expected b, c and d to be in, not %v", E)
+	}
+
+	var buf bytes.Buffer
+	check(t, tgf.ExportBAF(&buf, baf))
+	baf2, err := tgf.ImportBAF(&buf)
+	check(t, err)
+	checkAttacks(t, "imported attacks", args, baf2.Atks(), atks)
+	checkAttacks(t, "imported supports", args, baf2.Sups(), sups)
+}
+
+func TestValueBasedAF(t *testing.T) {
+	// a, promoting life, attacks b, promoting property, which attacks c,
+	// promoting life.
+	args := []dung.Arg{"a", "b", "c"}
+	atks := map[dung.Arg][]dung.Arg{"b": {"a"}, "c": {"b"}}
+	values := map[dung.Arg]dung.Value{"a": "life", "b": "property", "c": "life"}
+	vaf := dung.NewVAF(args, atks, values)
+	if n := len(vaf.Audiences()); n != 2 {
+		t.Errorf("expected 2 audiences, not %d", n)
+	}
+	if !vaf.ObjectivelyAccepted(dung.Preferred, "a") {
+		t.Errorf("expected a to be objectively accepted")
+	}
+	for _, arg := range []dung.Arg{"b", "c"} {
+		if vaf.ObjectivelyAccepted(dung.Preferred, arg) || !vaf.SubjectivelyAccepted(dung.Preferred, arg) {
+			t.Errorf("expected %s to be only subjectively accepted", arg)
+		}
+	}
+	audiences := vaf.AcceptingAudiences(dung.Preferred, "c")
+	if len(audiences) != 1 || audiences[0].String() != "life > property" {
+		t.Errorf("expected c to be accepted only by the audience life > property, not %v", audiences)
+	}
+	// an audience without preferences accepts a and c
+	a, err := dung.NewAudience(map[dung.Value][]dung.Value{})
+	check(t, err)
+	if !vaf.Accepted(dung.Grounded, a, "c") || vaf.Accepted(dung.Grounded, a, "b") {
+		t.Errorf("expected c but not b to be accepted by %v", a)
+	}
+	if _, err := dung.NewAudience(map[dung.Value][]dung.Value{"life": {"property"}, "property": {"life"}}); err == nil {
+		t.Errorf("expected an error for cyclic preferences")
+	}
+}
+
+func TestSETAF(t *testing.T) {
+	// a and b together attack c, which attacks a
+	f, err := apx.ImportSETAF(strings.NewReader("arg(a). arg(b). arg(c).\natt({a,b},c). att(c,a).\n"))
+	check(t, err)
+	if _, ok := f.AF(); ok {
+		t.Errorf("expected the collective attack not to be reducible")
+	}
+	if G := f.GroundedExtension(); !G.Equals(dung.NewArgSet("b")) {
+		t.Errorf("expected the grounded extension [b], not %v", G)
+	}
+	expected := []dung.ArgSet{dung.NewArgSet("a", "b"), dung.NewArgSet("b", "c")}
+	for _, s := range []dung.Semantics{dung.Preferred, dung.Stable} {
+		if actual := f.Extensions(s); len(actual) != 2 || !dung.EqualArgSetSlices(actual, expected) {
+			t.Errorf("expected %s extensions %v, not %v", s, expected, actual)
+		}
+	}
+	if actual := f.CompleteExtensions(); len(actual) != 3 {
+		t.Errorf("expected 3 complete extensions, not %v", actual)
+	}
+
+	// A SETAF whose only collective attack is subsumed by a single attack
+	var buf bytes.Buffer
+	f = dung.NewSETAF([]dung.Arg{"a", "b", "c"},
+		map[dung.Arg][][]dung.Arg{"c": {{"a", "b"}, {"a"}}})
+	check(t, apx.ExportSETAF(&buf, f))
+	f, err = apx.ImportSETAF(&buf)
+	check(t, err)
+	if af, ok := f.AF(); !ok || !dung.NewArgSet(af.Atks()["c"]...).Equals(dung.NewArgSet("a")) {
+		t.Errorf("expected the SETAF to reduce to an AF in which a attacks c")
+	}
+	// an attack by the empty set defeats its target unconditionally
+	buf.Reset()
+	f = dung.NewSETAF([]dung.Arg{"a", "b"},
+		map[dung.Arg][][]dung.Arg{"a": {{}}, "b": {{"a"}}})
+	check(t, apx.ExportSETAF(&buf, f))
+	f, err = apx.ImportSETAF(&buf)
+	check(t, err)
+	if actual := f.Atks()["a"]; len(actual) != 1 || len(actual[0]) != 0 {
+		t.Errorf("expected a to be attacked by the empty set, not %v", actual)
+	}
+	if G := f.GroundedExtension(); !G.Equals(dung.NewArgSet("b")) {
+		t.Errorf("expected the grounded extension [b], not %v", G)
+	}
+	for _, s := range []string{"att({,a},b).", "att({a,},b).", "att({a b},b)."} {
+		if _, err := apx.ImportSETAF(strings.NewReader("arg(a). arg(b). " + s)); err == nil {
+			t.Errorf("expected an error importing %s", s)
+		}
+	}
+	if _, err := apx.Import(strings.NewReader("arg(a). arg(b). att({a},b).")); err == nil {
+		t.Errorf("expected an error importing a collective attack into an AF")
+	}
+}
+
+func TestSETAFRandom(t *testing.T) {
+	// SETAFs with attacks by single arguments have the same
+	// extensions as the corresponding AFs
+	r := rand.New(rand.NewSource(9))
+	for i := 0; i < 100; i++ {
+		af := randomAF(r, 1+r.Intn(8), 0.05+0.4*r.Float64())
+		atks := make(map[dung.Arg][][]dung.Arg)
+		for arg, attackers := range af.Atks() {
+			for _, atk := range attackers {
+				atks[arg] = append(atks[arg], []dung.Arg{atk})
+			}
+		}
+		f := dung.NewSETAF(af.Args(), atks)
+		for _, s := range []dung.Semantics{dung.Grounded, dung.Complete, dung.Preferred, dung.Stable} {
+			l1, l2 := af.Extensions(s), f.Extensions(s)
+			if len(l1) != len(l2) || !dung.EqualArgSetSlices(l1, l2) {
+				t.Errorf("random AF %d: expected %s extensions %v, not %v", i, s, l1, l2)
+			}
+		}
+	}
+}
+
+func TestGradualSemantics(t *testing.T) {
+	// 1 attacks 2, which attacks 3, and 4 attacks itself
+	a4 := dung.Arg("4")
+	args := []dung.Arg{a1, a2, a3, a4}
+	atks := map[dung.Arg][]dung.Arg{a2: {a1}, a3: {a2}, a4: {a4}}
+	af := dung.NewAF(args, atks)
+	golden := (math.Sqrt(5) - 1) / 2
+	expected := map[dung.GradualSemantics]map[dung.Arg]float64{
+		dung.HCategorizer: {a1: 1, a2: 0.5, a3: 2.0 / 3, a4: golden},
+		dung.MaxBased:     {a1: 1, a2: 0.5, a3: 2.0 / 3, a4: golden},
+		dung.CardBased:    {a1: 1, a2: 1.0 / 3, a3: 3.0 / 7, a4: math.Sqrt(2) - 1},
+		dung.Counting:     {a1: 1, a2: 0.1, a3: 0.91, a4: 1 / 1.9},
+	}
+	for g, degrees := range expected {
+		actual := af.Degrees(g)
+		for arg, d := range degrees {
+			if math.Abs(actual[arg]-d) > 1e-6 {
+				t.Errorf("%s: expected degree %f of %s, not %f", g, d, arg, actual[arg])
+			}
+		}
+	}
+	// duplicate and undeclared attackers are ignored by the counting norm
+	af2 := dung.NewAF([]dung.Arg{a1, a2}, map[dung.Arg][]dung.Arg{a2: {a1, a1, "x"}})
+	if d := af2.CountingDegrees(dung.DefaultDamping)[a2]; math.Abs(d-0.1) > 1e-6 {
+		t.Errorf("counting: expected degree 0.1 of 2, not %f", d)
+	}
+	degrees := af.HCategorizerDegrees()
+	if dung.Compare(degrees, a3, a2) != 1 || dung.Compare(degrees, a2, a3) != -1 {
+		t.Errorf("expected 3 to be more acceptable than 2")
+	}
+	ranking := dung.Rank(degrees)
+	if fmt.Sprint(ranking) != "[[1] [3] [4] [2]]" {
+		t.Errorf("expected the ranking [[1] [3] [4] [2]], not %v", ranking)
+	}
+	// degrees within the precision of their neighbours form one class,
+	// although x and z differ by more than the precision
+	ranking = dung.Rank(map[dung.Arg]float64{"x": 0.5, "y": 0.5 + 6e-9, "z": 0.5 + 1.2e-8, "w": 0.1})
+	if fmt.Sprint(ranking) != "[[x y z] [w]]" {
+		t.Errorf("expected the ranking [[x y z] [w]], not %v", ranking)
+	}
+	if c := dung.RankingCorrelation(degrees, af.MaxBasedDegrees()); c != 1 {
+		t.Errorf("expected the h-categorizer and max-based rankings to agree, not %f", c)
+	}
+	reversed := make(map[dung.Arg]float64)
+	for arg, d := range degrees {
+		reversed[arg] = 1 - d
+	}
+	if c := dung.RankingCorrelation(degrees, reversed); c != -1 {
+		t.Errorf("expected the reversed ranking to disagree, not %f", c)
+	}
+}
+
+func TestAFModification(t *testing.T) {
+	af := dung.NewAF([]dung.Arg{a1, a2}, map[dung.Arg][]dung.Arg{})
+	af.AddArg(a3)
+	af.AddArg(a3)
+	af.AddAttack(a1, a2)
+	af.AddAttack(a2, a3)
+	af.AddAttack(a2, a3)
+	if len(af.Args()) != 3 || len(af.Atks()[a3]) != 1 {
+		t.Errorf("expected 3 arguments and 1 attacker of 3, not %v and %v", af.Args(), af.Atks()[a3])
+	}
+	if !af.GroundedExtension().Equals(dung.NewArgSet(a1, a3)) {
+		t.Errorf("expected the grounded extension {1,3}")
+	}
+	af.RemoveAttack(a1, a2)
+	af.RemoveArg(a3)
+	af.RemoveArg(a3)
+	if len(af.Args()) != 2 || len(af.Atks()) != 0 {
+		t.Errorf("expected 2 arguments and no attacks, not %v and %v", af.Args(), af.Atks())
+	}
+}
+
+func TestEvaluatorRandom(t *testing.T) {
+	// Compare the incrementally updated labelling and extensions with
+	// those computed from scratch, after each of a sequence of random
+	// modifications
+	r := rand.New(rand.NewSource(11))
+	for i := 0; i < 50; i++ {
+		af := randomAF(r, 1+r.Intn(6), 0.05+0.3*r.Float64())
+		e := dung.NewEvaluator(&af)
+		e.StableExtensions()
+		for j := 0; j < 20; j++ {
+			arg := func() dung.Arg { return dung.Arg(fmt.Sprintf("%d", r.Intn(8))) }
+			var op string
+			switch r.Intn(4) {
+			case 0:
+				a := arg()
+				op = fmt.Sprintf("add %s", a)
+				e.AddArg(a)
+			case 1:
+				a := arg()
+				op = fmt.Sprintf("remove %s", a)
+				e.RemoveArg(a)
+			case 2:
+				a, b := arg(), arg()
+				op = fmt.Sprintf("add %s -> %s", a, b)
+				e.AddAttack(a, b)
+			default:
+				a, b := arg(), arg()
+				op = fmt.Sprintf("remove %s -> %s", a, b)
+				e.RemoveAttack(a, b)
+			}
+			name := fmt.Sprintf("random AF %d, after %s", i, op)
+			ref := dung.NewAF(e.AF().Args(), e.AF().Atks())
+			if G := e.GroundedExtension(); !G.Equals(ref.GroundedExtension()) {
+				t.Errorf("%s: expected the grounded extension %v, not %v", name, ref.GroundedExtension(), G)
+			}
+			l1, l2 := ref.PreferredExtensions(), e.PreferredExtensions()
+			if len(l1) != len(l2) || !dung.EqualArgSetSlices(l1, l2) {
+				t.Errorf("%s: expected the preferred extensions %v, not %v", name, l1, l2)
+			}
+			l1, l2 = ref.StableExtensions(), e.StableExtensions()
+			if len(l1) != len(l2) || !dung.EqualArgSetSlices(l1, l2) {
+				t.Errorf("%s: expected the stable extensions %v, not %v", name, l1, l2)
+			}
+		}
+	}
+}
+
+func TestEnforcement(t *testing.T) {
+	// 1 attacks 2
+	af := dung.NewAF([]dung.Arg{a1, a2}, map[dung.Arg][]dung.Arg{a2: {a1}})
+	S := dung.NewArgSet(a2)
+	edits, err := af.EnforceExtension(dung.Stable, S, true, dung.AttackEdits)
+	if err != nil || fmt.Sprint(edits) != "[add 2 -> 1]" {
+		t.Errorf("expected the edits [add 2 -> 1], not %v (%v)", edits, err)
+	}
+	edits, err = af.EnforceExtension(dung.Stable, S, true, dung.ArgumentEdits)
+	if err != nil || fmt.Sprint(edits) != "[remove 1]" {
+		t.Errorf("expected the edits [remove 1], not %v (%v)", edits, err)
+	}
+	edits, err = af.EnforceExtension(dung.Grounded, S, true, dung.ExpansionEdits)
+	if err != nil || fmt.Sprint(edits) != "[add new add new -> 1]" {
+		t.Errorf("expected the edits [add new add new -> 1], not %v (%v)", edits, err)
+	}
+	edits, err = af.EnforceCredulous(dung.Preferred, a2, dung.AttackEdits)
+	if err != nil || len(edits) != 1 {
+		t.Errorf("expected a single edit, not %v (%v)", edits, err)
+	}
+	edits, err = af.EnforceSkeptical(dung.Preferred, a2, dung.AttackEdits)
+	if err != nil || fmt.Sprint(edits) != "[remove 1 -> 2]" {
+		t.Errorf("expected the edits [remove 1 -> 2], not %v (%v)", edits, err)
+	}
+	if _, err := af.EnforceExtension(dung.Grounded, dung.NewArgSet(a3), false, dung.AttackEdits); err != dung.ErrNotEnforceable {
+		t.Errorf("expected an argument not in the AF not to be enforceable, not %v", err)
+	}
+	limit := dung.EnforcementLimit
+	dung.EnforcementLimit = 1
+	if _, err := af.EnforceExtension(dung.Stable, S, true, dung.AttackEdits); err != dung.ErrEnforcementLimit {
+		t.Errorf("expected the search limit to be exceeded, not %v", err)
+	}
+	dung.EnforcementLimit = limit
+
+	// the AF exported with the edits is the edited AF
+	var buf bytes.Buffer
+	check(t, tgf.ExportEdits(&buf, af, []dung.Edit{{Op: dung.AddAttackEdit, From: a2, To: a1}}))
+	if !strings.Contains(buf.String(), "2 1 added\n") {
+		t.Errorf("expected the added attack to be labelled, not %q", buf.String())
+	}
+	buf.Reset()
+	check(t, tgf.ExportEdits(&buf, af, edits))
+	if len(af.Atks()[a2]) != 1 {
+		t.Errorf("expected the AF not to be modified")
+	}
+	af2, err := tgf.Import(&buf)
+	check(t, err)
+	if len(af2.Atks()[a2]) != 0 || !af2.SkepticallyInferred(dung.Preferred, a2) {
+		t.Errorf("expected the attack on 2 to be removed, not %v", af2.Atks())
+	}
+
+	r := rand.New(rand.NewSource(12))
+	for i := 0; i < 30; i++ {
+		af := randomAF(r, 1+r.Intn(4), 0.05+0.4*r.Float64())
+		arg := af.Args()[r.Intn(len(af.Args()))]
+		for _, s := range []dung.Semantics{dung.Grounded, dung.Preferred, dung.Stable} {
+			edits, err := af.EnforceExtension(s, dung.NewArgSet(arg), true, dung.AttackEdits|dung.ArgumentEdits)
+			af2 := dung.NewAF(append([]dung.Arg{}, af.Args()...), map[dung.Arg][]dung.Arg{})
+			for b, atks := range af.Atks() {
+				af2.Atks()[b] = append([]dung.Arg{}, atks...)
+			}
+			af2.Apply(edits)
+			if err != nil || !containsArgSet(af2.Extensions(s), dung.NewArgSet(arg)) {
+				t.Errorf("random AF %d: %v does not make {%s} a %s extension", i, edits, arg, s)
+			}
+		}
+	}
+}
+
+func countAttacks(af dung.AF) int {
+	n := 0
+	for _, arg := range af.Args() {
+		n += len(af.Atks()[arg])
+	}
+	return n
+}
+
+func TestGenerators(t *testing.T) {
+	generate := map[string]func(r *rand.Rand) dung.AF{
+		"er":   func(r *rand.Rand) dung.AF { return gen.ErdosRenyi(r, 8, 0.2) },
+		"ba":   func(r *rand.Rand) dung.AF { return gen.BarabasiAlbert(r, 8, 2) },
+		"grid": func(r *rand.Rand) dung.AF { return gen.Grid(r, 2, 4) },
+		"ws":   func(r *rand.Rand) dung.AF { return gen.WattsStrogatz(r, 8, 4, 0.3) },
+	}
+	// the number of attacks, if it does not depend on the seed
+	attacks := map[string]int{"ba": 13, "grid": 10, "ws": 16}
+	for name, f := range generate {
+		af1, af2 := f(rand.New(rand.NewSource(5))), f(rand.New(rand.NewSource(5)))
+		var buf1, buf2 bytes.Buffer
+		check(t, tgf.Export(&buf1, af1))
+		check(t, tgf.Export(&buf2, af2))
+		if buf1.String() != buf2.String() {
+			t.Errorf("%s: expected the same AF for the same seed, not %s and %s", name, buf1.String(), buf2.String())
+		}
+		if len(af1.Args()) != 8 {
+			t.Errorf("%s: expected 8 arguments, not %v", name, af1.Args())
+		}
+		if n, ok := attacks[name]; ok && countAttacks(af1) != n {
+			t.Errorf("%s: expected %d attacks, not %d", name, n, countAttacks(af1))
+		}
+		for _, arg := range af1.Args() {
+			if dung.NewArgSet(af1.Atks()[arg]...).Contains(arg) {
+				t.Errorf("%s: unexpected self attack of %s", name, arg)
+			}
+		}
+		// compare the solvers with the reference solver
+		for i := int64(0); i < 3; i++ {
+			crossCheckSolvers(t, fmt.Sprintf("%s AF %d", name, i), f(rand.New(rand.NewSource(i))))
+		}
+	}
+	if af := gen.BarabasiAlbert(rand.New(rand.NewSource(5)), -1, -2); len(af.Args()) != 0 {
+		t.Errorf("ba: expected no arguments, not %v", af.Args())
+	}
+	if af := gen.Grid(rand.New(rand.NewSource(5)), -2, -3); len(af.Args()) != 0 {
+		t.Errorf("grid: expected no arguments, not %v", af.Args())
+	}
+	// k is rounded down to an even number less than n, so there are n*k/2
+	// attacks and no self attacks
+	for _, c := range []struct{ n, k, attacks int }{{5, 7, 10}, {6, 6, 12}, {4, 3, 4}} {
+		af := gen.WattsStrogatz(rand.New(rand.NewSource(5)), c.n, c.k, 0.5)
+		if countAttacks(af) != c.attacks {
+			t.Errorf("ws %d %d: expected %d attacks, not %d", c.n, c.k, c.attacks, countAttacks(af))
+		}
+		for _, arg := range af.Args() {
+			if dung.NewArgSet(af.Atks()[arg]...).Contains(arg) {
+				t.Errorf("ws %d %d: unexpected self attack of %s", c.n, c.k, arg)
+			}
+		}
+	}
+	if af := gen.WattsStrogatz(rand.New(rand.NewSource(5)), -1, -2, 0.5); len(af.Args()) != 0 {
+		t.Errorf("ws: expected no arguments, not %v", af.Args())
+	}
+}
+
+// Attackers which are not arguments of the AF are never in an
+// extension, like self-attacking arguments, under every semantics.
+// The ranges of the extensions include self-attacking arguments, but
+// not external attackers, so stable and range-based semantics are not
+// compared.
+func TestExternalAttackers(t *testing.T) {
+	r := rand.New(rand.NewSource(17))
+	for i := 0; i < 100; i++ {
+		af := randomAF(r, 2+r.Intn(7), 0.1+0.3*r.Float64())
+		// remove some arguments, keeping their attacks
+		args, externals := []dung.Arg{}, []dung.Arg{}
+		for _, arg := range af.Args() {
+			if r.Intn(3) == 0 {
+				externals = append(externals, arg)
+			} else {
+				args = append(args, arg)
+			}
+		}
+		atks := make(map[dung.Arg][]dung.Arg)
+		for arg, attackers := range af.Atks() {
+			atks[arg] = append([]dung.Arg{}, attackers...)
+		}
+		for _, arg := range externals {
+			atks[arg] = append(atks[arg], arg)
+		}
+		af1, af2 := dung.NewAF(args, af.Atks()), dung.NewAF(af.Args(), atks)
+		crossCheckSolvers(t, fmt.Sprintf("random AF %d with external attackers", i), af1)
+		for _, solver := range []dung.Solver{dung.BacktrackingSolver, dung.SCCSolver, dung.SubsetSolver} {
+			af1.SetSolver(solver)
+			af2.SetSolver(solver)
+			for _, s := range []dung.Semantics{dung.Grounded, dung.Complete, dung.Preferred,
+				dung.Ideal, dung.Naive, dung.Admissible, dung.CF2} {
+				if l1, l2 := af1.Extensions(s), af2.Extensions(s); len(l1) != len(l2) || !dung.EqualArgSetSlices(l1, l2) {
+					t.Errorf("random AF %d (%s): expected the %s extensions %v, not %v", i, solver, s, l2, l1)
+				}
+			}
+		}
+	}
+}
+
+func TestStreamExtensions(t *testing.T) {
+	r := rand.New(rand.NewSource(13))
+	for i := 0; i < 20; i++ {
+		af := randomAF(r, 1+r.Intn(8), 0.05+0.4*r.Float64())
+		for _, solver := range []dung.Solver{dung.BacktrackingSolver, dung.SCCSolver, dung.SubsetSolver} {
+			af.SetSolver(solver)
+			for _, s := range []dung.Semantics{dung.Complete, dung.Preferred, dung.Naive} {
+				name := fmt.Sprintf("random AF %d (%s)", i, solver)
+				l1, l2 := af.Extensions(s), []dung.ArgSet{}
+				for E := range af.StreamExtensions(context.Background(), s, 0) {
+					l2 = append(l2, E)
+				}
+				if len(l1) != len(l2) || !dung.EqualArgSetSlices(l1, l2) {
+					t.Errorf("%s: expected the %s extensions %v, not %v", name, s, l1, l2)
+				}
+				l2 = []dung.ArgSet{}
+				err := af.EnumerateExtensions(context.Background(), s, 2, func(E dung.ArgSet) bool {
+					l2 = append(l2, E)
+					return false
+				})
+				if err != nil || len(l2) != len(l1) && len(l2) != 2 {
+					t.Errorf("%s: expected at most 2 %s extensions, not %v", name, s, l2)
+				}
+			}
+		}
+	}
+
+	// cancelling the enumeration of the naive extensions of a large AF
+	af := gen.ErdosRenyi(rand.New(rand.NewSource(2)), 60, 0.05)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	n := 0
+	err := af.EnumerateExtensions(ctx, dung.Naive, 0, func(E dung.ArgSet) bool {
+		n++
+		return false
+	})
+	if err != context.DeadlineExceeded || time.Since(start) > 5*time.Second {
+		t.Errorf("expected the enumeration to be cancelled, not %v after %d extensions", err, n)
+	}
+	if !af.Cancellable(dung.Naive) || af.Cancellable(dung.Ideal) {
+		t.Errorf("expected only the naive enumeration to be cancellable")
+	}
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	for E := range af.StreamExtensions(ctx, dung.Preferred, 0) {
+		t.Errorf("expected no extensions after cancellation, not %v", E)
+	}
+
+	count := 0
+	if !af.TraverseUntil(func(L dung.ArgSet) bool {
+		count++
+		return count == 3
+	}) || count != 3 {
+		t.Errorf("expected the traversal to stop after 3 subsets, not %d", count)
+	}
+}
+
+// The solvers find extensions of generated AFs with thousands of
+// arguments within seconds.
+func TestLargeGeneratedAFs(t *testing.T) {
+	generate := map[string]func(r *rand.Rand) dung.AF{
+		"er":   func(r *rand.Rand) dung.AF { return gen.ErdosRenyi(r, 2000, 0.0015) },
+		"ba":   func(r *rand.Rand) dung.AF { return gen.BarabasiAlbert(r, 3000, 2) },
+		"grid": func(r *rand.Rand) dung.AF { return gen.Grid(r, 50, 50) },
+		"ws":   func(r *rand.Rand) dung.AF { return gen.WattsStrogatz(r, 3000, 4, 0.1) },
+	}
+	for name, f := range generate {
+		for i := int64(0); i < 2; i++ {
+			af := f(rand.New(rand.NewSource(i)))
+			for _, solver := range []dung.Solver{dung.BacktrackingSolver, dung.SCCSolver} {
+				af.SetSolver(solver)
+				for _, s := range []dung.Semantics{dung.Preferred, dung.Stable} {
+					ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+					l := []dung.ArgSet{}
+					err := af.EnumerateExtensions(ctx, s, 1, func(E dung.ArgSet) bool {
+						l = append(l, E)
+						return false
+					})
+					cancel()
+					if err != nil {
+						t.Errorf("%s AF %d (%s): %s extension not found: %v", name, i, solver, s, err)
+						continue
+					}
+					if s == dung.Preferred && len(l) != 1 {
+						t.Errorf("%s AF %d (%s): expected a preferred extension, not %v", name, i, solver, l)
+					}
+					for _, E := range l {
+						if !af.IsExtension(s, E) {
+							t.Errorf("%s AF %d (%s): %v is not a %s extension", name, i, solver, E, s)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestEquivalence(t *testing.T) {
+	// 1 attacks itself and 2
+	af1 := dung.NewAF([]dung.Arg{a1, a2}, map[dung.Arg][]dung.Arg{a1: {a1}, a2: {a1}})
+	af2 := dung.NewAF([]dung.Arg{a1, a2}, map[dung.Arg][]dung.Arg{a1: {a1}})
+	if ok, _ := dung.StronglyEquivalent(dung.Stable, &af1, &af2); !ok {
+		t.Errorf("expected the AFs to be strongly equivalent w.r.t. stable semantics")
+	}
+	ok, w := dung.StandardEquivalent(dung.Preferred, &af1, &af2)
+	if ok || !w.First || !w.Extension.Equals(dung.NewArgSet()) {
+		t.Errorf("expected the preferred extension {} of the first AF as a witness, not %v", w.Extension)
+	}
+	ok, w = dung.StronglyEquivalent(dung.Grounded, &af1, &af2)
+	if ok || w.Extension == nil {
+		t.Errorf("expected a witness of the strong non-equivalence w.r.t. grounded semantics")
+	}
+
+	semantics := []dung.Semantics{dung.Grounded, dung.Complete, dung.Preferred,
+		dung.Stable, dung.SemiStable, dung.Stage, dung.Ideal, dung.Eager,
+		dung.Naive, dung.Admissible, dung.CF2, dung.Stage2}
+	r := rand.New(rand.NewSource(14))
+	for i := 0; i < 100; i++ {
+		// af2 differs from af1 by some attacks involving self-attacking
+		// arguments
+		af1 := randomAF(r, 1+r.Intn(4), 0.1+0.4*r.Float64())
+		args := af1.Args()
+		af2 := af1.Expand(dung.NewAF(nil, nil))
+		for j := 0; j < 2; j++ {
+			a, b := args[r.Intn(len(args))], args[r.Intn(len(args))]
+			if dung.NewArgSet(af2.Atks()[a]...).Contains(a) || dung.NewArgSet(af2.Atks()[b]...).Contains(b) {
+				if dung.NewArgSet(af2.Atks()[b]...).Contains(a) {
+					af2.RemoveAttack(a, b)
+				} else {
+					af2.AddAttack(a, b)
+				}
+			}
+		}
+		h := randomAF(r, 1+r.Intn(5), 0.3)
+		for _, s := range semantics {
+			name := fmt.Sprintf("random AF %d (%s)", i, s)
+			ok, w := dung.StronglyEquivalent(s, &af1, &af2)
+			if ok {
+				e1, e2 := af1.Expand(h), af2.Expand(h)
+				if ok, w := dung.StandardEquivalent(s, &e1, &e2); !ok {
+					t.Errorf("%s: expected the expanded AFs to be equivalent, not %v", name, w.Extension)
+				}
+				continue
+			}
+			if w.Extension == nil {
+				t.Errorf("%s: expected a witness of the strong non-equivalence of %v and %v", name, af1.String(), af2.String())
+				continue
+			}
+			e1, e2 := af1.Expand(w.Expansion), af2.Expand(w.Expansion)
+			if !w.First {
+				e1, e2 = e2, e1
+			}
+			if !e1.IsExtension(s, w.Extension) || e2.IsExtension(s, w.Extension) {
+				t.Errorf("%s: %v is not a witness", name, w)
+			}
+		}
+	}
+}
+
+func TestLabellings(t *testing.T) {
+	// 1 attacks 2, 3 and 4 attack each other, and 4 attacks 5
+	af := dung.NewAF([]dung.Arg{"1", "2", "3", "4", "5"},
+		map[dung.Arg][]dung.Arg{"2": {"1"}, "3": {"4"}, "4": {"3"}, "5": {"4"}})
+	expected := dung.Labelling{"1": dung.In, "2": dung.Out, "3": dung.Undecided, "4": dung.Undecided, "5": dung.Undecided}
+	if l := af.GroundedLabelling(); !l.Equals(expected) {
+		t.Errorf("expected grounded labelling %s, not %s", expected, l)
+	}
+	if s := expected.String(); s != "{in: [1], out: [2], undecided: [3,4,5]}" {
+		t.Errorf("unexpected string %s", s)
+	}
+	preferred := af.Labellings(dung.Preferred)
+	expectedPreferred := []dung.Labelling{
+		{"1": dung.In, "2": dung.Out, "3": dung.In, "4": dung.Out, "5": dung.In},
+		{"1": dung.In, "2": dung.Out, "3": dung.Out, "4": dung.In, "5": dung.Out},
+	}
+	if len(preferred) != len(expectedPreferred) {
+		t.Errorf("expected %d preferred labellings, not %v", len(expectedPreferred), preferred)
+	}
+	for _, l1 := range expectedPreferred {
+		found := false
+		for _, l2 := range preferred {
+			found = found || l1.Equals(l2)
+		}
+		if !found {
+			t.Errorf("expected the preferred labelling %s in %v", l1, preferred)
+		}
+	}
+
+	// The labellings of complete extensions are legal: an argument is in
+	// iff all its attackers are out, and out iff some attacker is in.
+	r := rand.New(rand.NewSource(17))
+	for i := 0; i < 50; i++ {
+		af := randomAF(r, 1+r.Intn(7), 0.05+0.4*r.Float64())
+		for _, s := range []dung.Semantics{dung.Grounded, dung.Complete, dung.Preferred, dung.Stable, dung.Naive, dung.CF2} {
+			extensions := af.Extensions(s)
+			labellings := af.Labellings(s)
+			if len(labellings) != len(extensions) {
+				t.Errorf("random AF %d (%s): expected %d labellings, not %d", i, s, len(extensions), len(labellings))
+				continue
+			}
+			for j, l := range labellings {
+				if len(l) != len(af.Args()) {
+					t.Errorf("random AF %d (%s): expected every argument to be labelled in %s", i, s, l)
+				}
+				if !l.AsExtension().Equals(extensions[j]) {
+					t.Errorf("random AF %d (%s): expected labelling of %s, not %s", i, s, extensions[j], l)
+				}
+				if s == dung.Naive || s == dung.CF2 {
+					continue
+				}
+				for _, arg := range af.Args() {
+					allOut, someIn := true, false
+					for _, atk := range af.Atks()[arg] {
+						allOut = allOut && l.Get(atk) == dung.Out
+						someIn = someIn || l.Get(atk) == dung.In
+					}
+					if (l.Get(arg) == dung.In) != allOut || (l.Get(arg) == dung.Out) != someIn {
+						t.Errorf("random AF %d (%s): illegal label of %s in %s of %s", i, s, arg, l, af.String())
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestProbabilisticAF(t *testing.T) {
+	// a is accepted if b is absent, or c, which attacks b, is present
+	for _, src := range []struct {
+		format string
+		text   string
+	}{
+		{"tgf", "a\nb 0.5\nc\n#\nb a\nc b 0.4\n"},
+		{"apx", "arg(a).\narg(b,0.5).\narg(c).\natt(b,a).\natt(c,b,0.4).\n"},
+	} {
+		var p dung.PAF
+		var err error
+		if src.format == "tgf" {
+			p, err = tgf.ImportPAF(strings.NewReader(src.text))
+		} else {
+			p, err = apx.ImportPAF(strings.NewReader(src.text))
+		}
+		check(t, err)
+		if x := p.AttackProbability("c", "b"); x != 0.4 {
+			t.Errorf("%s: expected the probability 0.4 of the attack of c on b, not %v", src.format, x)
+		}
+		for _, s := range []dung.Semantics{dung.Grounded, dung.Preferred} {
+			if x := p.ExactProbability(s, "a", false); math.Abs(x-0.7) > 1e-9 {
+				t.Errorf("%s (%s): expected the probability 0.7, not %v", src.format, s, x)
+			}
+		}
+	}
+	if _, err := apx.ImportPAF(strings.NewReader("arg(a,1.5).\n")); err == nil {
+		t.Errorf("expected an error for a probability greater than 1")
+	}
+
+	// compare the exact and sampled probabilities of random PAFs, and the
+	// probabilities of certain PAFs with the AF
+	r := rand.New(rand.NewSource(19))
+	for i := 0; i < 20; i++ {
+		af := randomAF(r, 1+r.Intn(5), 0.05+0.4*r.Float64())
+		certain := dung.NewPAF(af)
+		p := dung.NewPAF(af)
+		for _, arg := range af.Args() {
+			if r.Float64() < 0.5 {
+				check(t, p.SetArgProbability(arg, r.Float64()))
+			}
+			for _, atk := range af.Atks()[arg] {
+				if r.Float64() < 0.5 {
+					check(t, p.SetAttackProbability(atk, arg, r.Float64()))
+				}
+			}
+		}
+		for _, s := range []dung.Semantics{dung.Grounded, dung.Preferred, dung.Stable} {
+			for _, skeptical := range []bool{false, true} {
+				for _, arg := range af.Args() {
+					name := fmt.Sprintf("random AF %d (%s, %v, %s)", i, s, skeptical, arg)
+					inferred := af.CredulouslyInferred(s, arg)
+					if skeptical {
+						inferred = af.SkepticallyInferred(s, arg)
+					}
+					if x := certain.ExactProbability(s, arg, skeptical); (x == 1) != inferred || x != 0 && x != 1 {
+						t.Errorf("%s: unexpected probability %v of a certain AF", name, x)
+					}
+					exact := p.ExactProbability(s, arg, skeptical)
+					sampled := p.SampledProbability(rand.New(rand.NewSource(int64(i))), 2000, s, arg, skeptical)
+					if math.Abs(exact-sampled) > 0.05 {
+						t.Errorf("%s: expected a sampled probability close to %v, not %v", name, exact, sampled)
+					}
+				}
+			}
+		}
+	}
+}
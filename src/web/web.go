@@ -2,6 +2,8 @@ package web
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"html/template"
 	//	"io"
@@ -10,7 +12,7 @@ import (
 	"net/http"
 	"os/exec"
 	"path/filepath"
-	"strings"
+	"strconv"
 	"sync"
 	"time"
 
@@ -26,14 +28,34 @@ import (
 	"github.com/carneades/carneades-4/src/engine/caes/encoding/lkif"
 	"github.com/carneades/carneades-4/src/engine/caes/encoding/yaml"
 	"github.com/carneades/carneades-4/src/engine/dung"
+	"github.com/carneades/carneades-4/src/engine/dung/encoding/apx"
 	ddot "github.com/carneades/carneades-4/src/engine/dung/encoding/dot"
 	dgml "github.com/carneades/carneades-4/src/engine/dung/encoding/graphml"
 	"github.com/carneades/carneades-4/src/engine/dung/encoding/tgf"
 	"github.com/carneades/carneades-4/src/engine/validation"
 )
 
-const afLimit = 20   // max number of arguments handled by the Dung solver
-const timeLimit = 15 // seconds, for running Dot
+const afLimit = 20         // max number of arguments, for semantics which cannot be cancelled
+const extensionLimit = 100 // max number of extensions listed by the Dung solver
+const solverTimeLimit = 15 // seconds, for computing extensions and goals
+const abductionLimit = 3   // max size of the sets of statements computed by goal selection
+const timeLimit = 15       // seconds, for running Dot
+
+// The values of the semantics field of the Dung form
+var semanticsNames = map[string]dung.Semantics{
+	"grounded":    dung.Grounded,
+	"complete":    dung.Complete,
+	"preferred":   dung.Preferred,
+	"stable":      dung.Stable,
+	"semi-stable": dung.SemiStable,
+	"stage":       dung.Stage,
+	"ideal":       dung.Ideal,
+	"eager":       dung.Eager,
+	"naive":       dung.Naive,
+	"admissible":  dung.Admissible,
+	"cf2":         dung.CF2,
+	"stage2":      dung.Stage2,
+}
 
 type templateHandler struct {
 	once         sync.Once
@@ -159,7 +181,7 @@ func CarneadesServer(port string, templatesDir string) {
 			ag.Infer()
 			// evaluate the argument graph, using grounded semantics
 			// and update the labels of the statements in the argument graph
-			l := ag.GroundedLabelling()
+			l, ex := ag.ExplainedGroundedLabelling()
 			// fmt.Printf("labelling=%v\n", l)
 			ag.ApplyLabelling(l)
 
@@ -167,13 +189,13 @@ func CarneadesServer(port string, templatesDir string) {
 			case "yaml":
 				yaml.Export(w, ag)
 			case "graphml":
-				err = graphml.Export(w, ag)
+				err = graphml.ExportExplanations(w, ag, ex)
 				if err != nil {
 					errorTemplate.Execute(w, err.Error())
 					return
 				}
 			case "dot":
-				err = dot.Export(w, ag)
+				err = dot.ExportExplanations(w, ag, ex)
 				if err != nil {
 					errorTemplate.Execute(w, err.Error())
 					return
@@ -218,6 +240,7 @@ func CarneadesServer(port string, templatesDir string) {
 
 	dungHandler := func(w http.ResponseWriter, req *http.Request) {
 		semantics := req.FormValue("semantics")
+		inputFormat := req.FormValue("input-format")
 		outputFormat := req.FormValue("output-format")
 		file, _, err := req.FormFile("datafile")
 		if err != nil {
@@ -233,62 +256,68 @@ func CarneadesServer(port string, templatesDir string) {
 		var af dung.AF
 		rd := bytes.NewReader(data)
 
-		af, err = tgf.Import(rd)
+		switch inputFormat {
+		case "apx":
+			af, err = apx.Import(rd)
+		case "tgf", "":
+			af, err = tgf.Import(rd)
+		default:
+			errorTemplate.Execute(w, fmt.Sprintf("unknown or unsupported input format: %s\n", inputFormat))
+			return
+		}
 		if err != nil {
 			errorTemplate.Execute(w, err.Error())
 			return
-		} else if len(af.Args()) > afLimit {
-			errorTemplate.Execute(w, fmt.Sprintf("Argumentation frameworks with more than %v arguments are not supported by this server.\n", afLimit))
-			return
 		}
 
-		// evaluate the argumentation framework, using the selected semantics
-		var extensions []dung.ArgSet
+		// evaluate the argumentation framework, using the selected semantics,
+		// until the time limit is reached
+		s, ok := semanticsNames[semantics]
+		if !ok {
+			s = dung.Grounded
+		}
+		// the time limit does not apply to semantics which cannot be cancelled
+		if !af.Cancellable(s) && len(af.Args()) > afLimit {
+			w.WriteHeader(http.StatusBadRequest)
+			errorTemplate.Execute(w, fmt.Sprintf("Argumentation frameworks with more than %v arguments are not supported by this server for the %s semantics.\n", afLimit, s))
+			return
+		}
+		ctx, cancel := context.WithTimeout(req.Context(), solverTimeLimit*time.Second)
+		defer cancel()
 
-		switch semantics {
-		case "complete":
-			if outputFormat == "text" {
-				extensions = af.CompleteExtensions()
-			} else {
-				e, ok := af.SomeExtension(dung.Complete)
-				if ok {
-					extensions = []dung.ArgSet{e}
-				} else {
-					extensions = []dung.ArgSet{}
+		if outputFormat == "text" {
+			// stream the extensions as they are found
+			flusher, _ := w.(http.Flusher)
+			fmt.Fprintf(w, "[")
+			count := 0
+			err = af.EnumerateExtensions(ctx, s, extensionLimit, func(E dung.ArgSet) bool {
+				if count > 0 {
+					fmt.Fprintf(w, ",")
 				}
-			}
-		case "preferred":
-			if outputFormat == "text" {
-				extensions = af.PreferredExtensions()
-			} else {
-				e, ok := af.SomeExtension(dung.Preferred)
-				if ok {
-					extensions = []dung.ArgSet{e}
-				} else {
-					extensions = []dung.ArgSet{}
-				}
-			}
-		case "stable":
-			if outputFormat == "text" {
-				extensions = af.StableExtensions()
-			} else {
-				e, ok := af.SomeExtension(dung.Stable)
-				if ok {
-					extensions = []dung.ArgSet{e}
-				} else {
-					extensions = []dung.ArgSet{}
+				fmt.Fprintf(w, "%s", E)
+				count++
+				if flusher != nil {
+					flusher.Flush()
 				}
+				return false
+			})
+			fmt.Fprintf(w, "]\n")
+			if err != nil {
+				fmt.Fprintf(w, "The computation of the extensions was stopped after %v seconds.\n", solverTimeLimit)
+			} else if count == extensionLimit {
+				fmt.Fprintf(w, "Only the first %v extensions are listed.\n", extensionLimit)
 			}
-		default:
-			extensions = []dung.ArgSet{af.GroundedExtension()}
+			return
 		}
 
-		printExtensions := func(extensions []dung.ArgSet) {
-			s := []string{}
-			for _, E := range extensions {
-				s = append(s, E.String())
-			}
-			fmt.Fprintf(w, "[%s]\n", strings.Join(s, ","))
+		var extensions []dung.ArgSet
+		err = af.EnumerateExtensions(ctx, s, 1, func(E dung.ArgSet) bool {
+			extensions = append(extensions, E)
+			return true
+		})
+		if err != nil {
+			errorTemplate.Execute(w, fmt.Sprintf("No extension was found within %v seconds.\n", solverTimeLimit))
+			return
 		}
 
 		var as dung.ArgSet
@@ -331,8 +360,8 @@ func CarneadesServer(port string, templatesDir string) {
 					return
 				}
 			}
-		default: // text
-			printExtensions(extensions)
+		default:
+			fmt.Fprintf(w, "[%s]\n", as)
 		}
 	}
 
@@ -371,7 +400,7 @@ func CarneadesServer(port string, templatesDir string) {
 			fmt.Fprintf(w, "%q\n", err.Error())
 			return
 		}
-		l := ag.GroundedLabelling()
+		l := caes.NewEvaluator(ag).GroundedLabelling()
 		ag.ApplyLabelling(l)
 		w.WriteHeader(http.StatusOK)
 		if accept == "image/svg+xml" {
@@ -386,6 +415,95 @@ func CarneadesServer(port string, templatesDir string) {
 		}
 	}
 
+	// Select goals of an argument graph in YAML (including JSON) format, using
+	// abduction, and return the minimal sets of statements to be accepted or
+	// rejected to make the goal statement in or out, as a JSON list of
+	// objects with "accept" and "reject" lists of statement ids. The goal
+	// statement is given by the goal URL parameter, its label by the label
+	// parameter, in or out (default: in), and the maximum size of the sets
+	// by the max parameter (default: 2, at most abductionLimit). The search
+	// is stopped after solverTimeLimit seconds.
+	goalsHandler := func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Methods", "POST,OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+		// Stop here if it is a preflighted OPTIONS request
+		if req.Method == "OPTIONS" {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		badRequest := func(err error) {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "%q\n", err.Error())
+		}
+		// the parameters are read from the URL, since the body is YAML
+		query := req.URL.Query()
+		goal := query.Get("goal")
+		if goal == "" {
+			badRequest(fmt.Errorf("missing goal parameter"))
+			return
+		}
+		label := caes.In
+		switch query.Get("label") {
+		case "", "in":
+		case "out":
+			label = caes.Out
+		default:
+			badRequest(fmt.Errorf("unsupported label: %s; should be in or out", query.Get("label")))
+			return
+		}
+		max := 2
+		if m := query.Get("max"); m != "" {
+			n, err := strconv.Atoi(m)
+			if err != nil {
+				badRequest(err)
+				return
+			}
+			if n < 0 || n > abductionLimit {
+				badRequest(fmt.Errorf("unsupported max: %d; should be between 0 and %d", n, abductionLimit))
+				return
+			}
+			max = n
+		}
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			badRequest(err)
+			return
+		}
+		ag, err := yaml.Import(bytes.NewReader(body))
+		if err != nil {
+			badRequest(err)
+			return
+		}
+		err = ag.Infer()
+		if err != nil {
+			badRequest(err)
+			return
+		}
+		ctx, cancel := context.WithTimeout(req.Context(), solverTimeLimit*time.Second)
+		defer cancel()
+		sets, err := ag.AbduceContext(ctx, goal, label, max)
+		if err != nil && ctx.Err() != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintf(w, "%q\n", fmt.Sprintf("the search was stopped after %v seconds", solverTimeLimit))
+			return
+		} else if err != nil {
+			badRequest(err)
+			return
+		}
+		type set struct {
+			Accept []string `json:"accept"`
+			Reject []string `json:"reject"`
+		}
+		result := []set{}
+		for _, s := range sets {
+			result = append(result, set{Accept: s.Accepted, Reject: s.Rejected})
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(result)
+	}
+
 	http.Handle(root+"/", newTemplateHandler(templatesDir, "carneades.html"))
 	http.Handle(root+"/help", newTemplateHandler(templatesDir, "help.html"))
 	http.Handle(root+"/dataprot", newTemplateHandler(templatesDir, "dataprot.html"))
@@ -397,6 +515,7 @@ func CarneadesServer(port string, templatesDir string) {
 	http.HandleFunc(root+"/dung", dungHandler)
 	http.Handle(root+"/imprint", newTemplateHandler(templatesDir, "imprint.html"))
 	http.HandleFunc(root+"/eval-arg-graph", evalArgGraphHandler)
+	http.HandleFunc(root+"/goals", goalsHandler)
 
 	// start the web server
 	if err := http.ListenAndServe(":"+port, nil); err != nil {